@@ -4,7 +4,11 @@ import (
 	"context"
 	"log"
 	"notification_service/internal/adaptors/redis"
+	"notification_service/internal/adaptors/smtp"
 	"notification_service/internal/config"
+	"notification_service/internal/usecase"
+	"os"
+	"shared/events"
 )
 
 func main() {
@@ -20,5 +24,25 @@ func main() {
 		log.Fatalf("could not create redis subscriber: %v", err)
 	}
 
-	subscriber.Listen(context.Background(), "task_notifications")
+	consumerName := cfg.StreamConsumerName
+	if consumerName == "" {
+		// container hostnames are unique per replica
+		if consumerName, err = os.Hostname(); err != nil {
+			log.Fatalf("could not determine consumer name: %v", err)
+		}
+	}
+
+	streamOpts := redis.StreamOptions{
+		Group:       cfg.StreamConsumerGroup,
+		Consumer:    consumerName,
+		MaxAttempts: cfg.StreamMaxAttempts,
+		MinIdle:     cfg.StreamClaimMinIdle,
+	}
+
+	mailer := smtp.NewMailer(cfg.SMTPAddress, cfg.SMTPFrom, cfg.SMTPUsername, cfg.SMTPPassword)
+	emailer := usecase.NewEmailer(mailer)
+	go subscriber.ListenEmails(context.Background(), events.EmailStream, streamOpts, emailer)
+
+	notifier := usecase.NewNotifier()
+	subscriber.Listen(context.Background(), events.TaskEventsStream, streamOpts, notifier)
 }
@@ -5,6 +5,7 @@ go 1.22.4
 require (
 	github.com/go-redis/redis/v8 v8.11.5
 	github.com/spf13/viper v1.20.1
+	shared v0.0.0
 )
 
 require (
@@ -25,3 +26,5 @@ require (
 	golang.org/x/text v0.21.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+replace shared => ../../shared
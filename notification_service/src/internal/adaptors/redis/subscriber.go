@@ -2,16 +2,52 @@ package redis
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"shared/events"
+	"strings"
+	"time"
 
 	"github.com/go-redis/redis/v8"
 )
 
+// entries that failed StreamOptions.MaxAttempts times end up in "<stream>:dead",
+// with the error that stopped them
+const deadLetterSuffix = ":dead"
+
+// roughly how many entries a dead-letter stream keeps; the oldest are trimmed
+const deadLetterMaxLen = 10_000
+
+// how long an event id is remembered after handling, to skip redeliveries
+const processedTTL = 24 * time.Hour
+
 type Subscriber struct {
 	client *redis.Client
 }
 
+// receives every task event that could be decoded
+type EventHandler interface {
+	HandleTaskEvent(ctx context.Context, event *events.Event) error
+}
+
+// receives every email request that could be decoded
+type EmailHandler interface {
+	HandleEmail(ctx context.Context, email *events.Email) error
+}
+
+// decodes the payload of one entry. the returned id is used to skip
+// redeliveries and handle does the actual work.
+type decodeFunc func(payload []byte) (id string, handle func(ctx context.Context) error, err error)
+
+type StreamOptions struct {
+	Group       string
+	Consumer    string        // unique per replica
+	MaxAttempts int64         // deliveries before an entry is dead-lettered
+	MinIdle     time.Duration // pending time after which another consumer may take over an entry
+}
+
 func NewSubscriber(address string) (*Subscriber, error) {
 	client := redis.NewClient(&redis.Options{
 		Addr: address,
@@ -25,18 +61,198 @@ func NewSubscriber(address string) (*Subscriber, error) {
 	return &Subscriber{client: client}, nil
 }
 
-func (s *Subscriber) Listen(ctx context.Context, channelName string) {
-	pubsub := s.client.Subscribe(ctx, channelName)
+// consumes a stream of task events. blocks until ctx is done.
+func (s *Subscriber) Listen(ctx context.Context, stream string, opts StreamOptions, handler EventHandler) {
+	s.consume(ctx, stream, opts, func(payload []byte) (string, func(ctx context.Context) error, error) {
+		event, err := events.Decode(payload)
+		if err != nil {
+			return "", nil, err
+		}
+		return event.ID, func(ctx context.Context) error { return handler.HandleTaskEvent(ctx, event) }, nil
+	})
+}
+
+// consumes a stream of email requests. blocks until ctx is done.
+func (s *Subscriber) ListenEmails(ctx context.Context, stream string, opts StreamOptions, handler EmailHandler) {
+	s.consume(ctx, stream, opts, func(payload []byte) (string, func(ctx context.Context) error, error) {
+		email, err := events.DecodeEmail(payload)
+		if err != nil {
+			return "", nil, err
+		}
+		return email.ID, func(ctx context.Context) error { return handler.HandleEmail(ctx, email) }, nil
+	})
+}
+
+// consumes the stream as part of the consumer group. an entry is acked only
+// after it was handled, so entries survive restarts; entries left pending by a
+// crashed or failing consumer are reclaimed after MinIdle and retried until
+// MaxAttempts, then moved to the dead-letter stream.
+func (s *Subscriber) consume(ctx context.Context, stream string, opts StreamOptions, decode decodeFunc) {
+	err := s.client.XGroupCreateMkStream(ctx, stream, opts.Group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		log.Fatalf("Could not create consumer group '%s' on '%s': %v", opts.Group, stream, err)
+	}
+	log.Printf("Consuming '%s' as %s/%s. Waiting for entries...", stream, opts.Group, opts.Consumer)
+
+	lastClaim := time.Time{}
+	for ctx.Err() == nil {
+		if time.Since(lastClaim) >= opts.MinIdle {
+			s.reclaim(ctx, stream, opts, decode)
+			lastClaim = time.Now()
+		}
+
+		res, err := s.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    opts.Group,
+			Consumer: opts.Consumer,
+			Streams:  []string{stream, ">"},
+			Count:    10,
+			Block:    5 * time.Second,
+		}).Result()
+		if err != nil {
+			if !errors.Is(err, redis.Nil) && ctx.Err() == nil {
+				log.Printf("Could not read from '%s': %v", stream, err)
+				time.Sleep(time.Second)
+			}
+			continue
+		}
+		for _, str := range res {
+			for _, msg := range str.Messages {
+				s.process(ctx, stream, opts, msg, decode)
+			}
+		}
+	}
+}
+
+// takes over entries that have been pending for too long, including our own failed ones
+func (s *Subscriber) reclaim(ctx context.Context, stream string, opts StreamOptions, decode decodeFunc) {
+	start := "0-0"
+	for {
+		msgs, next, err := s.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream:   stream,
+			Group:    opts.Group,
+			Consumer: opts.Consumer,
+			MinIdle:  opts.MinIdle,
+			Start:    start,
+			Count:    50,
+		}).Result()
+		if err != nil {
+			log.Printf("Could not reclaim pending entries on '%s': %v", stream, err)
+			return
+		}
+		for _, msg := range msgs {
+			attempts, err := s.deliveryCount(ctx, stream, opts.Group, msg.ID)
+			if err != nil {
+				log.Printf("Could not read delivery count of %s: %v", msg.ID, err)
+				continue
+			}
+			if attempts > opts.MaxAttempts {
+				s.deadLetter(ctx, stream, opts.Group, msg, fmt.Sprintf("gave up after %d attempts", opts.MaxAttempts))
+				continue
+			}
+			s.process(ctx, stream, opts, msg, decode)
+		}
+		if next == "0-0" || len(msgs) == 0 {
+			return
+		}
+		start = next
+	}
+}
+
+func (s *Subscriber) process(ctx context.Context, stream string, opts StreamOptions, msg redis.XMessage, decode decodeFunc) {
+	payload, _ := msg.Values[events.PayloadField].(string)
+	id, handle, err := decode([]byte(payload))
+	if err != nil {
+		// retrying can't fix a malformed entry
+		s.deadLetter(ctx, stream, opts.Group, msg, err.Error())
+		return
+	}
+
+	// consumer groups hand each entry to one consumer, but a reclaimed entry may
+	// already have been handled by a consumer that was only slow
+	processedKey := "notification:processed:" + id
+	if n, err := s.client.Exists(ctx, processedKey).Result(); err == nil && n > 0 {
+		s.ack(ctx, stream, opts.Group, msg.ID)
+		return
+	}
+
+	if err := handle(ctx); err != nil {
+		// left pending; reclaim retries it once it has been idle for MinIdle
+		log.Printf("Could not handle entry %s (%s) from '%s': %v", msg.ID, id, stream, err)
+		return
+	}
+
+	if err := s.client.Set(ctx, processedKey, 1, processedTTL).Err(); err != nil {
+		log.Printf("Could not mark %s as processed: %v", id, err)
+	}
+	s.ack(ctx, stream, opts.Group, msg.ID)
+}
+
+func (s *Subscriber) deliveryCount(ctx context.Context, stream, group, id string) (int64, error) {
+	pending, err := s.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: stream,
+		Group:  group,
+		Start:  id,
+		End:    id,
+		Count:  1,
+	}).Result()
+	if err != nil {
+		return 0, err
+	}
+	if len(pending) == 0 {
+		return 0, fmt.Errorf("entry %s is no longer pending", id)
+	}
+	return pending[0].RetryCount, nil
+}
 
-	_, err := pubsub.Receive(ctx)
+// copies the entry to the dead-letter stream and acks the original
+func (s *Subscriber) deadLetter(ctx context.Context, stream, group string, msg redis.XMessage, reason string) {
+	values := map[string]interface{}{
+		"stream":    stream,
+		"source_id": msg.ID,
+		"error":     reason,
+	}
+	for k, v := range msg.Values {
+		values[k] = v
+	}
+	if payload, ok := values[events.PayloadField].(string); ok {
+		values[events.PayloadField] = redactPayload(payload)
+	}
+	deadLetterStream := stream + deadLetterSuffix
+	err := s.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: deadLetterStream,
+		MaxLen: deadLetterMaxLen,
+		Approx: true,
+		Values: values,
+	}).Err()
 	if err != nil {
-		log.Fatalf("Could not subscribe to channel '%s': %v", channelName, err)
+		log.Printf("Could not dead-letter entry %s, leaving it pending: %v", msg.ID, err)
+		return
 	}
+	log.Printf("Moved entry %s to '%s': %s", msg.ID, deadLetterStream, reason)
+	s.ack(ctx, stream, group, msg.ID)
+}
 
-	log.Printf("Subscribed to '%s' channel. Waiting for messages...", channelName)
-	ch := pubsub.Channel()
+// drops the link from email payloads: dead letters are kept for inspection,
+// and reset or verification links must not outlive the stream they came on.
+// payloads that aren't JSON objects are kept as they are.
+func redactPayload(payload string) string {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(payload), &fields); err != nil {
+		return payload
+	}
+	if _, ok := fields["link"]; !ok {
+		return payload
+	}
+	delete(fields, "link")
+	redacted, err := json.Marshal(fields)
+	if err != nil {
+		return payload
+	}
+	return string(redacted)
+}
 
-	for msg := range ch {
-		log.Printf("[Notification Received]: %s", msg.Payload)
+func (s *Subscriber) ack(ctx context.Context, stream, group, id string) {
+	if err := s.client.XAck(ctx, stream, group, id).Err(); err != nil {
+		log.Printf("Could not ack entry %s: %v", id, err)
 	}
 }
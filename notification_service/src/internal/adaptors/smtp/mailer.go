@@ -0,0 +1,49 @@
+package smtp
+
+import (
+	"fmt"
+	"mime"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// sends plain-text mail through an SMTP relay. locally that is mailhog,
+// which accepts anything and shows it in its web UI.
+type Mailer struct {
+	address string
+	from    string
+	auth    smtp.Auth
+}
+
+// username and password are optional; relays without auth are used as is
+func NewMailer(address, from, username, password string) *Mailer {
+	m := &Mailer{address: address, from: from}
+	if username != "" {
+		host := address
+		if i := strings.LastIndex(address, ":"); i >= 0 {
+			host = address[:i]
+		}
+		m.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return m
+}
+
+// the subject is sent as an encoded word, so it can't add headers of its own
+// and non-ASCII text survives
+func (m *Mailer) Send(to, subject, body string) error {
+	msg := strings.Join([]string{
+		"From: " + m.from,
+		"To: " + to,
+		"Subject: " + mime.QEncoding.Encode("utf-8", subject),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		body,
+	}, "\r\n")
+	if err := smtp.SendMail(m.address, m.auth, m.from, []string{to}, []byte(msg)); err != nil {
+		return fmt.Errorf("could not send mail to %s: %w", to, err)
+	}
+	return nil
+}
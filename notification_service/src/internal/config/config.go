@@ -1,11 +1,24 @@
 package config
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/spf13/viper"
 )
 
 type Config struct {
 	RedisAddress string `mapstructure:"REDIS_ADDRESS"`
+
+	StreamConsumerGroup string        `mapstructure:"STREAM_CONSUMER_GROUP"`
+	StreamConsumerName  string        `mapstructure:"STREAM_CONSUMER_NAME"` // defaults to the hostname
+	StreamMaxAttempts   int64         `mapstructure:"STREAM_MAX_ATTEMPTS"`
+	StreamClaimMinIdle  time.Duration `mapstructure:"STREAM_CLAIM_MIN_IDLE"`
+
+	SMTPAddress  string `mapstructure:"SMTP_ADDRESS"`
+	SMTPFrom     string `mapstructure:"SMTP_FROM"`
+	SMTPUsername string `mapstructure:"SMTP_USERNAME"` // optional
+	SMTPPassword string `mapstructure:"SMTP_PASSWORD"`
 }
 
 func LoadConfig(path string) (config Config, err error) {
@@ -14,11 +27,36 @@ func LoadConfig(path string) (config Config, err error) {
 	viper.SetConfigType("env")
 	viper.AutomaticEnv()
 
+	viper.SetDefault("STREAM_CONSUMER_GROUP", "notification_service")
+	viper.SetDefault("STREAM_CONSUMER_NAME", "")
+	viper.SetDefault("STREAM_MAX_ATTEMPTS", 5)
+	viper.SetDefault("STREAM_CLAIM_MIN_IDLE", 30*time.Second)
+	viper.SetDefault("SMTP_ADDRESS", "localhost:1025")
+	viper.SetDefault("SMTP_FROM", "Task Manager <no-reply@taskmanager.local>")
+	viper.SetDefault("SMTP_USERNAME", "")
+	viper.SetDefault("SMTP_PASSWORD", "")
+
 	err = viper.ReadInConfig()
 	if err != nil {
 		return
 	}
 
-	err = viper.Unmarshal(&config)
+	if err = viper.Unmarshal(&config); err != nil {
+		return
+	}
+	err = config.validate()
 	return
 }
+
+// rejects settings the stream consumers can't run with
+func (c Config) validate() error {
+	if c.StreamMaxAttempts <= 0 {
+		return fmt.Errorf("STREAM_MAX_ATTEMPTS must be positive, got %d", c.StreamMaxAttempts)
+	}
+	// an entry is reclaimed once it was pending this long, so a consumer
+	// still working on it would see it handled twice
+	if c.StreamClaimMinIdle <= 0 {
+		return fmt.Errorf("STREAM_CLAIM_MIN_IDLE must be positive, got %s", c.StreamClaimMinIdle)
+	}
+	return nil
+}
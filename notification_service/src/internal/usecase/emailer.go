@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"shared/events"
+	"strings"
+	"time"
+)
+
+// delivers a rendered email
+type MailSender interface {
+	Send(to, subject, body string) error
+}
+
+// turns email requests from other services into actual mail
+type Emailer struct {
+	sender MailSender
+}
+
+func NewEmailer(sender MailSender) *Emailer {
+	return &Emailer{sender: sender}
+}
+
+func (e *Emailer) HandleEmail(ctx context.Context, email *events.Email) error {
+	subject, body, err := renderEmail(email)
+	if err != nil {
+		return err
+	}
+	if strings.ContainsAny(email.To, "\r\n") {
+		return fmt.Errorf("invalid recipient %q", email.To)
+	}
+	if err := e.sender.Send(email.To, subject, body); err != nil {
+		return err
+	}
+	log.Printf("[Email to user %d] %s (request %s)", email.UserID, subject, email.ID)
+	return nil
+}
+
+func renderEmail(email *events.Email) (subject, body string, err error) {
+	switch email.Kind {
+	case events.EmailPasswordReset:
+		subject = "Reset your password"
+		body = fmt.Sprintf("Hi %s,\n\n"+
+			"Someone asked to reset the password of your account. If that was you, open the link below to choose a new one:\n\n"+
+			"%s\n\n"+
+			"The link works once and expires at %s. If you didn't ask for this, you can ignore this email.\n",
+			email.Username, email.Link, email.ExpiresAt.Format(time.RFC1123))
+	case events.EmailVerification:
+		subject = "Confirm your email address"
+		body = fmt.Sprintf("Hi %s,\n\n"+
+			"Please confirm that this is your email address by opening the link below:\n\n"+
+			"%s\n\n"+
+			"The link expires at %s. If you didn't create an account, you can ignore this email.\n",
+			email.Username, email.Link, email.ExpiresAt.Format(time.RFC1123))
+	case events.EmailTeamInvite:
+		subject = fmt.Sprintf("You have been invited to join %s", email.TeamName)
+		body = fmt.Sprintf("Hi,\n\n"+
+			"%s invited you to join the team %s. Open the link below to accept; you will need an account with this email address:\n\n"+
+			"%s\n\n"+
+			"The invite expires at %s.\n",
+			email.InvitedBy, email.TeamName, email.Link, email.ExpiresAt.Format(time.RFC1123))
+	default:
+		return "", "", fmt.Errorf("no template for email kind %q", email.Kind)
+	}
+	return subject, body, nil
+}
@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"shared/events"
+	"strings"
+)
+
+// turns task events into notifications for the people involved.
+// delivery is a log line for now.
+type Notifier struct{}
+
+func NewNotifier() *Notifier {
+	return &Notifier{}
+}
+
+func (n *Notifier) HandleTaskEvent(ctx context.Context, event *events.Event) error {
+	recipients := event.Task.Participants()
+	var message string
+	switch event.Type {
+	case events.TaskCreated:
+		message = fmt.Sprintf("Task %d '%s' was created.", event.Task.ID, event.Task.Title)
+	case events.TaskUpdated:
+		message = fmt.Sprintf("Task %d '%s' was updated: %s.", event.Task.ID, event.Task.Title, describeChanges(event.Changes))
+	case events.TaskDeleted:
+		message = fmt.Sprintf("Task %d '%s' was deleted.", event.Task.ID, event.Task.Title)
+	case events.TaskUnblocked:
+		// only the people working on the task need to know they can start
+		message = fmt.Sprintf("Task %d '%s' is no longer blocked: all of its prerequisites are closed.", event.Task.ID, event.Task.Title)
+		recipients = event.Task.Assignees
+		if len(recipients) == 0 {
+			recipients = []int{event.Task.UserID}
+		}
+	case events.CommentCreated:
+		if event.Comment == nil {
+			return fmt.Errorf("comment event %s has no comment", event.ID)
+		}
+		// comments go to the people mentioned in them and to the task's watchers
+		message = fmt.Sprintf("New comment on task %d '%s': %s", event.Task.ID, event.Task.Title, event.Comment.Body)
+		recipients = union(event.Comment.Mentions, event.Task.Watchers)
+	default:
+		return fmt.Errorf("no notification for event type %q", event.Type)
+	}
+
+	// everyone involved hears about it, except whoever made the change
+	for _, userID := range recipients {
+		if userID == event.Actor.UserID {
+			continue
+		}
+		log.Printf("[Notification for user %d] %s (event %s by user %d)", userID, message, event.ID, event.Actor.UserID)
+	}
+	return nil
+}
+
+// ids from a followed by those of b not in a
+func union(a, b []int) []int {
+	seen := make(map[int]bool, len(a)+len(b))
+	var ids []int
+	for _, id := range append(append([]int{}, a...), b...) {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func describeChanges(changes []events.FieldChange) string {
+	if len(changes) == 0 {
+		return "no changes"
+	}
+	parts := make([]string, 0, len(changes))
+	for _, c := range changes {
+		parts = append(parts, fmt.Sprintf("%s %v -> %v", c.Field, c.Before, c.After))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,81 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Emails are requested by the user service and sent by the notification
+// service. They travel on their own stream, separate from task events.
+
+// redis stream email requests are appended to, in the PayloadField field.
+// the entries hold single-use links, so the stream is kept short.
+const EmailStream = "email_requests"
+
+type EmailKind string
+
+const (
+	EmailPasswordReset EmailKind = "email.password_reset"
+	EmailVerification  EmailKind = "email.verification"
+	EmailTeamInvite    EmailKind = "email.team_invite"
+)
+
+func (k EmailKind) IsKnown() bool {
+	switch k {
+	case EmailPasswordReset, EmailVerification, EmailTeamInvite:
+		return true
+	}
+	return false
+}
+
+// an email to send to a single user. the notification service owns the wording.
+type Email struct {
+	Version    int       `json:"version"`
+	ID         string    `json:"id"`
+	Kind       EmailKind `json:"kind"`
+	OccurredAt time.Time `json:"occurred_at"`
+	UserID     int       `json:"user_id"` // 0 when the recipient has no account yet, e.g. for invites
+	To         string    `json:"to"`
+	Username   string    `json:"username"`
+	Link       string    `json:"link,omitempty"`       // what the user should open, e.g. a reset or verification link
+	ExpiresAt  time.Time `json:"expires_at,omitempty"` // when Link stops working
+	TeamName   string    `json:"team_name,omitempty"`  // for team invites
+	InvitedBy  string    `json:"invited_by,omitempty"` // username of whoever sent the invite
+}
+
+// builds an email request with a fresh id and timestamp
+func NewEmail(kind EmailKind, userID int, to, username string) *Email {
+	return &Email{
+		Version:    SchemaVersion,
+		ID:         newID(),
+		Kind:       kind,
+		OccurredAt: time.Now().UTC(),
+		UserID:     userID,
+		To:         to,
+		Username:   username,
+	}
+}
+
+func EncodeEmail(e *Email) ([]byte, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode email: %w", err)
+	}
+	return data, nil
+}
+
+// parses a published email request, rejecting versions and kinds this build doesn't understand
+func DecodeEmail(data []byte) (*Email, error) {
+	var e Email
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, fmt.Errorf("could not decode email: %w", err)
+	}
+	if e.Version != SchemaVersion {
+		return nil, fmt.Errorf("unsupported email version %d", e.Version)
+	}
+	if !e.Kind.IsKnown() {
+		return nil, fmt.Errorf("unknown email kind %q", e.Kind)
+	}
+	return &e, nil
+}
@@ -0,0 +1,154 @@
+package events
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Contract between the task service (producer) and the notification service
+// (consumer) for task events. Both import this package, so this package is the
+// only definition of the wire format.
+
+// bumped whenever a field changes meaning or is removed. adding optional fields doesn't need a bump.
+const SchemaVersion = 1
+
+// redis stream task events are appended to. each entry carries the encoded
+// event in the PayloadField field.
+const (
+	TaskEventsStream = "task_events"
+	PayloadField     = "event"
+)
+
+type Type string
+
+const (
+	TaskCreated Type = "task.created"
+	// not sent when a label is renamed, recolored or deleted: that changes
+	// every task carrying it, and none of them was edited. consumers see the
+	// new labels with the task's next event.
+	TaskUpdated Type = "task.updated"
+	TaskDeleted Type = "task.deleted"
+	// a task.updated for a task whose last open prerequisite was closed
+	TaskUnblocked Type = "task.unblocked"
+
+	CommentCreated Type = "comment.created"
+)
+
+func (t Type) IsKnown() bool {
+	switch t {
+	case TaskCreated, TaskUpdated, TaskDeleted, TaskUnblocked, CommentCreated:
+		return true
+	}
+	return false
+}
+
+// envelope shared by every event
+type Event struct {
+	Version    int           `json:"version"`
+	ID         string        `json:"id"`
+	Type       Type          `json:"type"`
+	OccurredAt time.Time     `json:"occurred_at"`
+	Actor      Actor         `json:"actor"`
+	Task       TaskSnapshot  `json:"task"`              // state after the change, or the last state for deletions
+	Changes    []FieldChange `json:"changes,omitempty"` // only set for task.updated and task.unblocked
+	Comment    *Comment      `json:"comment,omitempty"` // only set for comment.* events
+}
+
+// who caused the event
+type Actor struct {
+	UserID int `json:"user_id"`
+}
+
+type TaskSnapshot struct {
+	ID          int        `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Status      string     `json:"status"`
+	Priority    string     `json:"priority"`
+	DueAt       *time.Time `json:"due_at"`
+	UserID      int        `json:"user_id"` // creator
+	TeamID      *int       `json:"team_id,omitempty"`
+	ParentID    *int       `json:"parent_id,omitempty"`
+	BlockedBy   []int      `json:"blocked_by,omitempty"` // ids of the tasks this one depends on
+	Labels      []string   `json:"labels,omitempty"`     // label names
+	Assignees   []int      `json:"assignees,omitempty"`
+	Watchers    []int      `json:"watchers,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// the creator, assignees and watchers of the task, each listed once
+func (s TaskSnapshot) Participants() []int {
+	seen := map[int]bool{}
+	var ids []int
+	for _, id := range append(append([]int{s.UserID}, s.Assignees...), s.Watchers...) {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// a comment on the event's task
+type Comment struct {
+	ID       int    `json:"id"`
+	AuthorID int    `json:"author_id"`
+	Body     string `json:"body"`
+	Mentions []int  `json:"mentions,omitempty"` // ids of the users @mentioned in the body
+}
+
+// one modified field of a task.updated event
+type FieldChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// builds an event with a fresh id and timestamp
+func New(eventType Type, actor Actor, task TaskSnapshot) *Event {
+	return &Event{
+		Version:    SchemaVersion,
+		ID:         newID(),
+		Type:       eventType,
+		OccurredAt: time.Now().UTC(),
+		Actor:      actor,
+		Task:       task,
+	}
+}
+
+func Encode(e *Event) ([]byte, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode event: %w", err)
+	}
+	return data, nil
+}
+
+// parses a published event, rejecting versions and types this build doesn't understand
+func Decode(data []byte) (*Event, error) {
+	var e Event
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, fmt.Errorf("could not decode event: %w", err)
+	}
+	if e.Version != SchemaVersion {
+		return nil, fmt.Errorf("unsupported event version %d", e.Version)
+	}
+	if !e.Type.IsKnown() {
+		return nil, fmt.Errorf("unknown event type %q", e.Type)
+	}
+	return &e, nil
+}
+
+// random RFC 4122 version 4 uuid
+func newID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("events: could not read random bytes: %v", err))
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -1,13 +1,17 @@
 package main
 
 import (
+	"context"
+	"expvar"
 	"log"
 	"net/http"
+	"os"
 	"task_service/internal/adaptors/grpcclient"
 	"task_service/internal/adaptors/persistance"
 	"task_service/internal/adaptors/redis"
 	"task_service/internal/config"
 	"task_service/internal/interfaces/input/api/rest/handler"
+	authMiddleware "task_service/internal/interfaces/input/api/rest/middleware"
 	"task_service/internal/usecase"
 
 	"github.com/go-chi/chi/v5"
@@ -20,6 +24,13 @@ func main() {
 		log.Fatalf("could not load config: %v", err)
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrateCommand(cfg, os.Args[2:]); err != nil {
+			log.Fatalf("migrate: %v", err)
+		}
+		return
+	}
+
 	dbStore, err := persistance.NewDBStore(cfg.DBSource)
 	if err != nil {
 		log.Fatalf("could not connect to database: %v", err)
@@ -35,17 +46,81 @@ func main() {
 		log.Fatalf("could not connect to redis: %v", err)
 	}
 
-	taskUsecase := usecase.NewTaskUsecase(dbStore, userClient, redisCache)
+	taskUsecase := usecase.NewTaskUsecase(dbStore, userClient, redisCache, usecase.TaskUsecaseConfig{
+		RequireVerifiedUsers:  cfg.RequireVerifiedUsers,
+		RequireClosedSubtasks: cfg.RequireClosedSubtasks,
+	})
+
+	commentUsecase := usecase.NewCommentUsecase(dbStore, taskUsecase, userClient)
+	labelUsecase := usecase.NewLabelUsecase(dbStore, dbStore, userClient)
+	viewUsecase := usecase.NewViewUsecase(dbStore, taskUsecase, userClient)
+
+	outboxRelay := usecase.NewOutboxRelay(dbStore, redisCache, usecase.OutboxRelayConfig{
+		PollInterval: cfg.OutboxPollInterval,
+		BatchSize:    cfg.OutboxBatchSize,
+		MinBackoff:   cfg.OutboxMinBackoff,
+		MaxBackoff:   cfg.OutboxMaxBackoff,
+	})
+	go outboxRelay.Run(context.Background())
+
+	// outbox relay metrics. expvar also exposes the command line and memory
+	// stats, so they are kept off the public port.
+	if cfg.DebugAddress != "" {
+		debugMux := http.NewServeMux()
+		debugMux.Handle("/debug/vars", expvar.Handler())
+		go func() {
+			log.Printf("Debug endpoints on %s", cfg.DebugAddress)
+			if err := http.ListenAndServe(cfg.DebugAddress, debugMux); err != nil {
+				log.Printf("debug server stopped: %v", err)
+			}
+		}()
+	}
 
 	taskHandler := handler.NewTaskHandler(taskUsecase)
+	commentHandler := handler.NewCommentHandler(commentUsecase)
+	labelHandler := handler.NewLabelHandler(labelUsecase)
+	viewHandler := handler.NewViewHandler(viewUsecase)
 
 	r := chi.NewRouter()
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
 
-	r.Post("/tasks", taskHandler.CreateTask)
-	r.Get("/tasks", taskHandler.ListTasks)
-	r.Put("/tasks/{id}", taskHandler.UpdateTask)
+	// every task endpoint needs a token issued by the user service
+	r.Group(func(r chi.Router) {
+		r.Use(authMiddleware.AuthMiddleware(cfg.JWTSecretKey))
+		r.Post("/tasks", taskHandler.CreateTask)
+		r.Get("/tasks", taskHandler.ListTasks)
+		r.Get("/tasks/{id}", taskHandler.GetTask)
+		r.Put("/tasks/{id}", taskHandler.UpdateTask)
+		r.Patch("/tasks/{id}", taskHandler.PatchTask)
+		r.Delete("/tasks/{id}", taskHandler.DeleteTask)
+		r.Get("/tasks/{id}/children", taskHandler.ListChildren)
+		r.Put("/tasks/{id}/parent", taskHandler.SetParent)
+		r.Post("/tasks/{id}/dependencies", taskHandler.AddDependency)
+		r.Delete("/tasks/{id}/dependencies/{depends_on_id}", taskHandler.RemoveDependency)
+		r.Post("/tasks/{id}/assignees", taskHandler.AddAssignees)
+		r.Delete("/tasks/{id}/assignees/{user_id}", taskHandler.RemoveAssignee)
+		r.Post("/tasks/{id}/watchers", taskHandler.AddWatchers)
+		r.Delete("/tasks/{id}/watchers/{user_id}", taskHandler.RemoveWatcher)
+		r.Post("/tasks/{id}/comments", commentHandler.CreateComment)
+		r.Get("/tasks/{id}/comments", commentHandler.ListComments)
+		r.Put("/tasks/{id}/comments/{comment_id}", commentHandler.UpdateComment)
+		r.Delete("/tasks/{id}/comments/{comment_id}", commentHandler.DeleteComment)
+		r.Post("/tasks/{id}/labels", labelHandler.AttachLabel)
+		r.Delete("/tasks/{id}/labels/{label_id}", labelHandler.DetachLabel)
+		r.Post("/labels", labelHandler.CreateLabel)
+		r.Get("/labels", labelHandler.ListLabels)
+		r.Put("/labels/{id}", labelHandler.UpdateLabel)
+		r.Delete("/labels/{id}", labelHandler.DeleteLabel)
+		r.Post("/views", viewHandler.CreateView)
+		r.Get("/views", viewHandler.ListViews)
+		r.Get("/views/{id}", viewHandler.GetView)
+		r.Put("/views/{id}", viewHandler.UpdateView)
+		r.Delete("/views/{id}", viewHandler.DeleteView)
+		r.Get("/views/{id}/tasks", viewHandler.ListViewTasks)
+		r.Put("/views/{id}/default", viewHandler.SetDefaultView)
+		r.Delete("/views/default", viewHandler.ClearDefaultView)
+	})
 
 	log.Printf("Task Service starting on %s", cfg.ServerAddress)
 	if err := http.ListenAndServe(cfg.ServerAddress, r); err != nil {
@@ -40,3 +40,68 @@ func (c *UserClient) GetUser(ctx context.Context, userID int32) (*pb.GetUserResp
 
 	return res, nil
 }
+
+// calls the BatchGetUsers rpc. ids that don't exist come back in MissingIds rather than as an error.
+func (c *UserClient) BatchGetUsers(ctx context.Context, userIDs []int32) (*pb.BatchGetUsersResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	res, err := c.client.BatchGetUsers(ctx, &pb.BatchGetUsersRequest{Ids: userIDs})
+	if err != nil {
+		return nil, fmt.Errorf("grpc call to BatchGetUsers failed: %w", err)
+	}
+
+	return res, nil
+}
+
+// calls the GetUsersByUsernames rpc. unknown usernames come back in MissingUsernames.
+func (c *UserClient) GetUsersByUsernames(ctx context.Context, usernames []string) (*pb.GetUsersByUsernamesResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	res, err := c.client.GetUsersByUsernames(ctx, &pb.GetUsersByUsernamesRequest{Usernames: usernames})
+	if err != nil {
+		return nil, fmt.Errorf("grpc call to GetUsersByUsernames failed: %w", err)
+	}
+
+	return res, nil
+}
+
+// calls the ValidateUsers rpc.
+func (c *UserClient) ValidateUsers(ctx context.Context, userIDs []int32) (*pb.ValidateUsersResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	res, err := c.client.ValidateUsers(ctx, &pb.ValidateUsersRequest{Ids: userIDs})
+	if err != nil {
+		return nil, fmt.Errorf("grpc call to ValidateUsers failed: %w", err)
+	}
+
+	return res, nil
+}
+
+// calls the GetTeamMembership rpc. non-members get IsMember false, not an error.
+func (c *UserClient) GetTeamMembership(ctx context.Context, teamID, userID int32) (*pb.GetTeamMembershipResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	res, err := c.client.GetTeamMembership(ctx, &pb.GetTeamMembershipRequest{TeamId: teamID, UserId: userID})
+	if err != nil {
+		return nil, fmt.Errorf("grpc call to GetTeamMembership failed: %w", err)
+	}
+
+	return res, nil
+}
+
+// calls the ListUserTeams rpc.
+func (c *UserClient) ListUserTeams(ctx context.Context, userID int32) (*pb.ListUserTeamsResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	res, err := c.client.ListUserTeams(ctx, &pb.ListUserTeamsRequest{UserId: userID})
+	if err != nil {
+		return nil, fmt.Errorf("grpc call to ListUserTeams failed: %w", err)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,108 @@
+package persistance
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"task_service/internal/core/comments"
+
+	"github.com/lib/pq"
+)
+
+const commentColumns = `id, task_id, author_id, body, mentions, created_at, updated_at`
+
+func scanComment(row rowScanner, comment *comments.Comment) error {
+	var mentions []int64
+	if err := row.Scan(&comment.ID, &comment.TaskID, &comment.AuthorID, &comment.Body, pq.Array(&mentions), &comment.CreatedAt, &comment.UpdatedAt); err != nil {
+		return err
+	}
+	comment.Mentions = toInts(mentions)
+	return nil
+}
+
+func (store *DBStore) CreateComment(ctx context.Context, comment *comments.Comment) error {
+	query := `INSERT INTO task_comments (task_id, author_id, body, mentions) VALUES ($1, $2, $3, $4) RETURNING ` + commentColumns
+	err := scanComment(store.conn(ctx).QueryRowContext(ctx, query, comment.TaskID, comment.AuthorID, comment.Body, pq.Array(comment.Mentions)), comment)
+	if err != nil {
+		return fmt.Errorf("could not create comment: %w", err)
+	}
+	return nil
+}
+
+// fetches a comment of the task that hasn't been deleted
+func (store *DBStore) GetComment(ctx context.Context, taskID, id int) (*comments.Comment, error) {
+	query := `SELECT ` + commentColumns + ` FROM task_comments WHERE id = $1 AND task_id = $2 AND deleted_at IS NULL`
+	comment := &comments.Comment{}
+	if err := scanComment(store.conn(ctx).QueryRowContext(ctx, query, id, taskID), comment); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("comment with id %d: %w", id, comments.ErrCommentNotFound)
+		}
+		return nil, fmt.Errorf("could not get comment: %w", err)
+	}
+	return comment, nil
+}
+
+// retrieves one page of comments, oldest first
+func (store *DBStore) ListComments(ctx context.Context, filter comments.ListFilter) (*comments.CommentPage, error) {
+	limit := filter.Limit
+	if limit <= 0 || limit > comments.MaxPageSize {
+		limit = comments.DefaultPageSize
+	}
+	// one extra row tells us whether there is a next page
+	query := `SELECT ` + commentColumns + ` FROM task_comments
+		WHERE task_id = $1 AND id > $2 AND deleted_at IS NULL ORDER BY id LIMIT $3`
+	rows, err := store.conn(ctx).QueryContext(ctx, query, filter.TaskID, filter.AfterID, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("could not query comments: %w", err)
+	}
+	defer rows.Close()
+	commentList := make([]comments.Comment, 0, limit+1)
+	for rows.Next() {
+		var comment comments.Comment
+		if err := scanComment(rows, &comment); err != nil {
+			return nil, fmt.Errorf("could not scan comment row: %w", err)
+		}
+		commentList = append(commentList, comment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating comment rows: %w", err)
+	}
+
+	page := &comments.CommentPage{Comments: commentList}
+	if len(commentList) > limit {
+		page.Comments = commentList[:limit]
+		page.NextCursor = comments.EncodeCursor(page.Comments[limit-1])
+	}
+	return page, nil
+}
+
+// replaces the body and mentions of a comment that hasn't been deleted
+func (store *DBStore) UpdateComment(ctx context.Context, comment *comments.Comment) error {
+	query := `UPDATE task_comments SET body = $1, mentions = $2, updated_at = now()
+		WHERE id = $3 AND task_id = $4 AND deleted_at IS NULL RETURNING ` + commentColumns
+	err := scanComment(store.conn(ctx).QueryRowContext(ctx, query, comment.Body, pq.Array(comment.Mentions), comment.ID, comment.TaskID), comment)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("comment with id %d: %w", comment.ID, comments.ErrCommentNotFound)
+		}
+		return fmt.Errorf("could not update comment: %w", err)
+	}
+	return nil
+}
+
+// marks the comment deleted; the row is kept
+func (store *DBStore) DeleteComment(ctx context.Context, taskID, id int) error {
+	query := `UPDATE task_comments SET deleted_at = now() WHERE id = $1 AND task_id = $2 AND deleted_at IS NULL`
+	res, err := store.conn(ctx).ExecContext(ctx, query, id, taskID)
+	if err != nil {
+		return fmt.Errorf("could not delete comment: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("could not delete comment: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("comment with id %d: %w", id, comments.ErrCommentNotFound)
+	}
+	return nil
+}
@@ -3,14 +3,17 @@ package persistance
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"log"
+	"shared/migrate"
+	"strconv"
 	"strings"
 	"task_service/internal/core/tasks"
+	"task_service/migrations"
 	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 type DBStore struct {
@@ -18,6 +21,18 @@ type DBStore struct {
 }
 
 func NewDBStore(dbSource string) (*DBStore, error) {
+	db, err := OpenDB(dbSource)
+	if err != nil {
+		return nil, err
+	}
+	if err := runMigrations(db); err != nil {
+		return nil, fmt.Errorf("could not run migrations: %w", err)
+	}
+	return &DBStore{DB: db}, nil
+}
+
+// opens and pings the database without touching the schema
+func OpenDB(dbSource string) (*sql.DB, error) {
 	db, err := sql.Open("postgres", dbSource)
 	if err != nil {
 		return nil, fmt.Errorf("could not open database: %w", err)
@@ -26,68 +41,312 @@ func NewDBStore(dbSource string) (*DBStore, error) {
 		return nil, fmt.Errorf("could not ping database: %w", err)
 	}
 	log.Println("Task service database connection successful.")
-	if err := runMigrations(db); err != nil {
-		return nil, fmt.Errorf("could not run migrations: %w", err)
-	}
-	return &DBStore{DB: db}, nil
+	return db, nil
 }
 
-// reads and executes the sql file
+// migrator over the migrations embedded in the binary
+func NewMigrator(db *sql.DB) (*migrate.Migrator, error) {
+	return migrate.New(db, migrations.FS)
+}
+
+// applies any pending migrations. safe to call from several replicas at once.
 func runMigrations(db *sql.DB) error {
-	migration, err := ioutil.ReadFile("migrations/init.sql")
+	migrator, err := NewMigrator(db)
 	if err != nil {
-		return fmt.Errorf("could not read migration file: %w", err)
+		return err
 	}
-	_, err = db.Exec(string(migration))
+	applied, err := migrator.Up(context.Background())
 	if err != nil {
-		return fmt.Errorf("could not execute migration: %w", err)
+		return err
 	}
-	log.Println("Task service database migration successful.")
+	log.Printf("Task service database migration successful, %d migration(s) applied.", applied)
 	return nil
 }
 
-func (store *DBStore) CreateTask(ctx context.Context, task *tasks.Task) error {
-	query := `INSERT INTO tasks (title, description, user_id) VALUES ($1, $2, $3) RETURNING id, status, created_at, updated_at`
-	err := store.DB.QueryRowContext(ctx, query, task.Title, task.Description, task.UserID).Scan(
+// columns selected whenever a full task is read, in the order scanTask expects.
+// assignees and watchers come along as arrays, so one query still loads a whole task.
+// the subtask counts behind Progress are computed the same way.
+const taskColumns = "id, title, description, status, priority, due_at, user_id, " +
+	"ARRAY(SELECT p.user_id FROM task_people p WHERE p.task_id = tasks.id AND p.relation = 'assignee' ORDER BY p.user_id), " +
+	"ARRAY(SELECT p.user_id FROM task_people p WHERE p.task_id = tasks.id AND p.relation = 'watcher' ORDER BY p.user_id), " +
+	"team_id, parent_id, " +
+	"(SELECT count(*) FROM tasks c WHERE c.parent_id = tasks.id AND c.status <> 'cancelled'), " +
+	"(SELECT count(*) FROM tasks c WHERE c.parent_id = tasks.id AND c.status = 'done'), " +
+	"ARRAY(SELECT d.depends_on_id FROM task_dependencies d WHERE d.task_id = tasks.id ORDER BY d.depends_on_id), " +
+	"ARRAY(SELECT d.task_id FROM task_dependencies d WHERE d.depends_on_id = tasks.id ORDER BY d.task_id), " +
+	"COALESCE(blocked_from, ''), " +
+	"COALESCE((SELECT json_agg(json_build_object('id', l.id, 'name', l.name, 'color', l.color) ORDER BY lower(l.name)) " +
+	"FROM task_labels tl JOIN labels l ON l.id = tl.label_id WHERE tl.task_id = tasks.id), '[]'), " +
+	"created_at, updated_at, version"
+
+// satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// extra receives any columns selected after taskColumns
+func scanTask(row rowScanner, task *tasks.Task, extra ...interface{}) error {
+	var dueAt sql.NullTime
+	var teamID, parentID sql.NullInt64
+	var subtasks, subtasksDone int
+	var taskLabels []byte
+	var assignees, watchers, blockedBy, blocks []int64
+	dest := []interface{}{
 		&task.ID,
+		&task.Title,
+		&task.Description,
 		&task.Status,
+		&task.Priority,
+		&dueAt,
+		&task.UserID,
+		pq.Array(&assignees),
+		pq.Array(&watchers),
+		&teamID,
+		&parentID,
+		&subtasks,
+		&subtasksDone,
+		pq.Array(&blockedBy),
+		pq.Array(&blocks),
+		&task.BlockedFrom,
+		&taskLabels,
 		&task.CreatedAt,
 		&task.UpdatedAt,
-	)
+		&task.Version,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return err
+	}
+	task.DueAt = nil
+	if dueAt.Valid {
+		task.DueAt = &dueAt.Time
+	}
+	task.Assignees = toInts(assignees)
+	task.Watchers = toInts(watchers)
+	task.TeamID = nil
+	if teamID.Valid {
+		id := int(teamID.Int64)
+		task.TeamID = &id
+	}
+	task.ParentID = nil
+	if parentID.Valid {
+		id := int(parentID.Int64)
+		task.ParentID = &id
+	}
+	task.Progress = tasks.Progress(subtasks, subtasksDone)
+	task.BlockedBy = toInts(blockedBy)
+	task.Blocks = toInts(blocks)
+	task.Labels = nil
+	if err := json.Unmarshal(taskLabels, &task.Labels); err != nil {
+		return fmt.Errorf("could not decode task labels: %w", err)
+	}
+	return nil
+}
+
+func toInts(ids []int64) []int {
+	out := make([]int, len(ids))
+	for i, id := range ids {
+		out[i] = int(id)
+	}
+	return out
+}
+
+func (store *DBStore) CreateTask(ctx context.Context, task *tasks.Task) error {
+	if task.Priority == "" {
+		task.Priority = tasks.DefaultPriority
+	}
+	query := `INSERT INTO tasks (title, description, priority, due_at, user_id, team_id, parent_id) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING ` + taskColumns
+	err := scanTask(store.conn(ctx).QueryRowContext(ctx, query, task.Title, task.Description, task.Priority, task.DueAt, task.UserID, task.TeamID, task.ParentID), task)
 	if err != nil {
 		return fmt.Errorf("could not create task: %w", err)
 	}
 	return nil
 }
 
-// retrieves a list of tasks
-func (store *DBStore) ListTasks(ctx context.Context, userID, status string) ([]tasks.Task, error) {
-	query := "SELECT id, title, description, status, user_id, created_at, updated_at FROM tasks"
+// fetches a single task by its id
+func (store *DBStore) GetTask(ctx context.Context, id int) (*tasks.Task, error) {
+	return store.getTask(ctx, id, "")
+}
+
+// same as GetTask but row-locks the task until the surrounding transaction ends
+func (store *DBStore) GetTaskForUpdate(ctx context.Context, id int) (*tasks.Task, error) {
+	return store.getTask(ctx, id, " FOR UPDATE")
+}
+
+func (store *DBStore) getTask(ctx context.Context, id int, lockClause string) (*tasks.Task, error) {
+	query := `SELECT ` + taskColumns + ` FROM tasks WHERE id = $1` + lockClause
+	task := &tasks.Task{}
+	err := scanTask(store.conn(ctx).QueryRowContext(ctx, query, id), task)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("task with id %d: %w", id, tasks.ErrTaskNotFound)
+		}
+		return nil, fmt.Errorf("could not get task: %w", err)
+	}
+	return task, nil
+}
+
+// maps sort fields to their columns; only whitelisted columns ever reach the query
+var sortColumns = map[tasks.SortField]string{
+	tasks.SortCreatedAt: "created_at",
+	tasks.SortUpdatedAt: "updated_at",
+	tasks.SortTitle:     "title",
+	tasks.SortStatus:    "status",
+	tasks.SortRelevance: searchRank,
+}
+
+// retrieves one page of tasks using keyset pagination on (sort column, id)
+func (store *DBStore) ListTasks(ctx context.Context, filter tasks.ListFilter) (*tasks.TaskPage, error) {
+	column, ok := sortColumns[filter.Sort.Field]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown field %q", tasks.ErrInvalidSort, filter.Sort.Field)
+	}
+	direction, comparison := "ASC", ">"
+	if filter.Sort.Desc {
+		direction, comparison = "DESC", "<"
+	}
+
+	selectList, from := taskColumns, "tasks"
 	var conditions []string
 	var args []interface{}
 	argID := 1
-	if userID != "" {
+	search := len(filter.Search) > 0
+	if search {
+		tsquery, searchArgs := searchQuery(filter.Search, argID)
+		selectList += ", " + searchColumns
+		from += ", (SELECT " + tsquery + " AS query) search"
+		conditions = append(conditions, "search_vector @@ search.query")
+		args = append(args, searchArgs...)
+		argID += len(searchArgs)
+	} else if filter.Sort.Field == tasks.SortRelevance {
+		return nil, fmt.Errorf("%w: relevance needs a search", tasks.ErrInvalidSort)
+	}
+	query := "SELECT " + selectList + " FROM " + from
+	if filter.UserID != 0 {
 		conditions = append(conditions, fmt.Sprintf("user_id = $%d", argID))
-		args = append(args, userID)
+		args = append(args, filter.UserID)
+		argID++
+	}
+	if filter.TeamID != 0 {
+		conditions = append(conditions, fmt.Sprintf("team_id = $%d", argID))
+		args = append(args, filter.TeamID)
 		argID++
 	}
-	if status != "" {
+	if filter.ParentID != 0 {
+		conditions = append(conditions, fmt.Sprintf("parent_id = $%d", argID))
+		args = append(args, filter.ParentID)
+		argID++
+	}
+	if len(filter.Labels) > 0 {
+		// labels are matched by name, so same-named labels of different scopes count as one
+		match := fmt.Sprintf("FROM task_labels tl JOIN labels l ON l.id = tl.label_id WHERE tl.task_id = tasks.id AND lower(l.name) = ANY($%d)", argID)
+		if filter.MatchAllLabels {
+			conditions = append(conditions, fmt.Sprintf("(SELECT count(DISTINCT lower(l.name)) %s) = $%d", match, argID+1))
+			args = append(args, pq.Array(filter.Labels), len(filter.Labels))
+			argID += 2
+		} else {
+			conditions = append(conditions, "EXISTS (SELECT 1 "+match+")")
+			args = append(args, pq.Array(filter.Labels))
+			argID++
+		}
+	}
+	if filter.AssigneeID != 0 {
+		conditions = append(conditions, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM task_people p WHERE p.task_id = tasks.id AND p.relation = '%s' AND p.user_id = $%d)", tasks.RelationAssignee, argID))
+		args = append(args, filter.AssigneeID)
+		argID++
+	}
+	if filter.WatcherID != 0 {
+		conditions = append(conditions, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM task_people p WHERE p.task_id = tasks.id AND p.relation = '%s' AND p.user_id = $%d)", tasks.RelationWatcher, argID))
+		args = append(args, filter.WatcherID)
+		argID++
+	}
+	if filter.VisibleTo != 0 {
+		conditions = append(conditions, fmt.Sprintf(
+			"(user_id = $%d OR EXISTS (SELECT 1 FROM task_people p WHERE p.task_id = tasks.id AND p.user_id = $%d))", argID, argID))
+		args = append(args, filter.VisibleTo)
+		argID++
+	}
+	if filter.Status != "" {
 		conditions = append(conditions, fmt.Sprintf("status = $%d", argID))
-		args = append(args, status)
+		args = append(args, filter.Status)
 		argID++
 	}
+	if filter.Priority != "" {
+		conditions = append(conditions, fmt.Sprintf("priority = $%d", argID))
+		args = append(args, filter.Priority)
+		argID++
+	}
+	if filter.DueBefore != nil {
+		conditions = append(conditions, fmt.Sprintf("due_at < $%d", argID))
+		args = append(args, *filter.DueBefore)
+		argID++
+	}
+	if filter.DueAfter != nil {
+		conditions = append(conditions, fmt.Sprintf("due_at > $%d", argID))
+		args = append(args, *filter.DueAfter)
+		argID++
+	}
+	if filter.Overdue {
+		conditions = append(conditions, fmt.Sprintf("due_at < now() AND status NOT IN ('%s', '%s')", tasks.StatusDone, tasks.StatusCancelled))
+	}
+	if filter.Filter != nil {
+		condition, filterArgs, err := compileFilter(filter.Filter, argID)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, condition)
+		args = append(args, filterArgs...)
+		argID += len(filterArgs)
+	}
+	if filter.Cursor != nil {
+		var value interface{} = filter.Cursor.Value
+		if filter.Sort.Field == tasks.SortCreatedAt || filter.Sort.Field == tasks.SortUpdatedAt {
+			t, err := time.Parse(time.RFC3339Nano, filter.Cursor.Value)
+			if err != nil {
+				return nil, tasks.ErrInvalidCursor
+			}
+			value = t
+		}
+		if filter.Sort.Field == tasks.SortRelevance {
+			rank, err := strconv.ParseFloat(filter.Cursor.Value, 32)
+			if err != nil {
+				return nil, tasks.ErrInvalidCursor
+			}
+			value = float32(rank)
+		}
+		conditions = append(conditions, fmt.Sprintf("(%s, id) %s ($%d, $%d)", column, comparison, argID, argID+1))
+		args = append(args, value, filter.Cursor.ID)
+		argID += 2
+	}
 	if len(conditions) > 0 {
 		query += " WHERE " + strings.Join(conditions, " AND ")
 	}
-	rows, err := store.DB.QueryContext(ctx, query, args...)
+
+	limit := filter.Limit
+	if limit <= 0 || limit > tasks.MaxPageSize {
+		limit = tasks.DefaultPageSize
+	}
+	// one extra row tells us whether there is a next page
+	query += fmt.Sprintf(" ORDER BY %s %s, id %s LIMIT $%d", column, direction, direction, argID)
+	args = append(args, limit+1)
+
+	rows, err := store.conn(ctx).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("could not query tasks: %w", err)
 	}
 	defer rows.Close()
-	var taskList []tasks.Task
+	taskList := make([]tasks.Task, 0, limit+1)
 	for rows.Next() {
 		var task tasks.Task
-		if err := rows.Scan(&task.ID, &task.Title, &task.Description, &task.Status, &task.UserID, &task.CreatedAt, &task.UpdatedAt); err != nil {
+		if search {
+			var rank float32
+			var highlight tasks.Highlight
+			if err := scanTask(rows, &task, &rank, &highlight.Title, &highlight.Description); err != nil {
+				return nil, fmt.Errorf("could not scan task row: %w", err)
+			}
+			task.Rank, task.Highlight = &rank, &highlight
+		} else if err := scanTask(rows, &task); err != nil {
 			return nil, fmt.Errorf("could not scan task row: %w", err)
 		}
 		taskList = append(taskList, task)
@@ -95,61 +354,47 @@ func (store *DBStore) ListTasks(ctx context.Context, userID, status string) ([]t
 	if err = rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating task rows: %w", err)
 	}
-	return taskList, nil
-}
-
-func (store *DBStore) UpdateTask(ctx context.Context, task *tasks.Task) (*tasks.Task, error) {
-	var setClauses []string
-	var args []interface{}
-	argID := 1
-
-	// Check each field and add it to the SET clause if it's not a zero value.
-	if task.Title != "" {
-		setClauses = append(setClauses, fmt.Sprintf("title = $%d", argID))
-		args = append(args, task.Title)
-		argID++
-	}
-	if task.Description != "" {
-		setClauses = append(setClauses, fmt.Sprintf("description = $%d", argID))
-		args = append(args, task.Description)
-		argID++
-	}
-	if task.Status != "" {
-		setClauses = append(setClauses, fmt.Sprintf("status = $%d", argID))
-		args = append(args, task.Status)
-		argID++
-	}
 
-	// if no fields aree provided to update
-	if len(setClauses) == 0 {
-		return nil, fmt.Errorf("no fields to update")
+	page := &tasks.TaskPage{Tasks: taskList}
+	if len(taskList) > limit {
+		page.Tasks = taskList[:limit]
+		page.NextCursor = tasks.EncodeCursor(filter.Sort, page.Tasks[limit-1])
 	}
+	return page, nil
+}
 
-	setClauses = append(setClauses, fmt.Sprintf("updated_at = $%d", argID))
-	args = append(args, time.Now())
-	argID++
-
-	args = append(args, task.ID)
-
-	query := fmt.Sprintf("UPDATE tasks SET %s WHERE id = $%d RETURNING id, title, description, status, user_id, created_at, updated_at",
-		strings.Join(setClauses, ", "), argID)
-
+// writes every field PUT and PATCH can change, so empty values and a nil DueAt
+// clear what was there. a status changed by hand is never undone by the
+// dependency logic, so a new status clears blocked_from; on the right of SET,
+// status is still the old value.
+func (store *DBStore) ReplaceTask(ctx context.Context, task *tasks.Task) (*tasks.Task, error) {
+	query := `UPDATE tasks SET title = $1, description = $2, status = $3, priority = $4, due_at = $5,
+		blocked_from = CASE WHEN status = $3 THEN blocked_from END,
+		updated_at = now(), version = version + 1
+		WHERE id = $6 RETURNING ` + taskColumns
 	updatedTask := &tasks.Task{}
-	err := store.DB.QueryRowContext(ctx, query, args...).Scan(
-		&updatedTask.ID,
-		&updatedTask.Title,
-		&updatedTask.Description,
-		&updatedTask.Status,
-		&updatedTask.UserID,
-		&updatedTask.CreatedAt,
-		&updatedTask.UpdatedAt,
-	)
-
+	err := scanTask(store.conn(ctx).QueryRowContext(ctx, query,
+		task.Title, task.Description, task.Status, task.Priority, task.DueAt, task.ID), updatedTask)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("task with id %d not found", task.ID)
+			return nil, fmt.Errorf("task with id %d: %w", task.ID, tasks.ErrTaskNotFound)
 		}
-		return nil, fmt.Errorf("could not update task: %w", err)
+		return nil, fmt.Errorf("could not replace task: %w", err)
 	}
 	return updatedTask, nil
 }
+
+func (store *DBStore) DeleteTask(ctx context.Context, id int) error {
+	res, err := store.conn(ctx).ExecContext(ctx, `DELETE FROM tasks WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("could not delete task: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("could not delete task: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("task with id %d: %w", id, tasks.ErrTaskNotFound)
+	}
+	return nil
+}
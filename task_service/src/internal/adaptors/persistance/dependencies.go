@@ -0,0 +1,80 @@
+package persistance
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"task_service/internal/core/tasks"
+)
+
+// arbitrary but fixed advisory lock key, held while dependencies are added
+const dependencyLockKey int64 = 7_262_917_004
+
+// serializes dependency changes for the current transaction, so two concurrent
+// additions can't each pass the cycle check and still form a loop together.
+func (store *DBStore) LockTaskDependencies(ctx context.Context) error {
+	if _, err := store.conn(ctx).ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, dependencyLockKey); err != nil {
+		return fmt.Errorf("could not lock task dependencies: %w", err)
+	}
+	return nil
+}
+
+// makes taskID depend on dependsOnID; adding an existing dependency is not an error
+func (store *DBStore) AddDependency(ctx context.Context, taskID, dependsOnID int) error {
+	query := `INSERT INTO task_dependencies (task_id, depends_on_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+	res, err := store.conn(ctx).ExecContext(ctx, query, taskID, dependsOnID)
+	if err != nil {
+		return fmt.Errorf("could not add dependency: %w", err)
+	}
+	return store.touchTask(ctx, taskID, res)
+}
+
+// removing a dependency that doesn't exist is not an error
+func (store *DBStore) RemoveDependency(ctx context.Context, taskID, dependsOnID int) error {
+	query := `DELETE FROM task_dependencies WHERE task_id = $1 AND depends_on_id = $2`
+	res, err := store.conn(ctx).ExecContext(ctx, query, taskID, dependsOnID)
+	if err != nil {
+		return fmt.Errorf("could not remove dependency: %w", err)
+	}
+	return store.touchTask(ctx, taskID, res)
+}
+
+// reports whether taskID depends on otherID, directly or through other tasks
+func (store *DBStore) DependsOn(ctx context.Context, taskID, otherID int) (bool, error) {
+	query := `WITH RECURSIVE prerequisites (id) AS (
+			SELECT depends_on_id FROM task_dependencies WHERE task_id = $1
+			UNION
+			SELECT d.depends_on_id FROM task_dependencies d JOIN prerequisites p ON d.task_id = p.id
+		)
+		SELECT EXISTS (SELECT 1 FROM prerequisites WHERE id = $2)`
+	var found bool
+	if err := store.conn(ctx).QueryRowContext(ctx, query, taskID, otherID).Scan(&found); err != nil {
+		return false, fmt.Errorf("could not check dependencies: %w", err)
+	}
+	return found, nil
+}
+
+// number of direct prerequisites that are neither done nor cancelled
+func (store *DBStore) CountOpenPrerequisites(ctx context.Context, id int) (int, error) {
+	query := `SELECT count(*) FROM task_dependencies d JOIN tasks t ON t.id = d.depends_on_id
+		WHERE d.task_id = $1 AND t.status NOT IN ($2, $3)`
+	var count int
+	if err := store.conn(ctx).QueryRowContext(ctx, query, id, tasks.StatusDone, tasks.StatusCancelled).Scan(&count); err != nil {
+		return 0, fmt.Errorf("could not count prerequisites: %w", err)
+	}
+	return count, nil
+}
+
+// status change made by the dependency logic. blockedFrom is the status to restore
+// later, or empty when the task isn't blocked by its prerequisites.
+func (store *DBStore) SetBlockedStatus(ctx context.Context, id int, status tasks.Status, blockedFrom tasks.Status) error {
+	var from sql.NullString
+	if blockedFrom != "" {
+		from = sql.NullString{String: string(blockedFrom), Valid: true}
+	}
+	query := `UPDATE tasks SET status = $1, blocked_from = $2, updated_at = now(), version = version + 1 WHERE id = $3`
+	if _, err := store.conn(ctx).ExecContext(ctx, query, status, from, id); err != nil {
+		return fmt.Errorf("could not update task status: %w", err)
+	}
+	return nil
+}
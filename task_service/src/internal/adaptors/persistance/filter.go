@@ -0,0 +1,158 @@
+package persistance
+
+import (
+	"fmt"
+	"strings"
+	"task_service/internal/core/tasks"
+
+	"github.com/lib/pq"
+)
+
+// columns behind the plain filter fields. fields without a column are handled
+// by subqueries in compileComparison.
+var filterColumns = map[tasks.FilterField]string{
+	tasks.FilterStatus:      "status",
+	tasks.FilterPriority:    "priority",
+	tasks.FilterTitle:       "title",
+	tasks.FilterDescription: "description",
+	tasks.FilterCreatedAt:   "created_at",
+	tasks.FilterUpdatedAt:   "updated_at",
+	tasks.FilterDueAt:       "due_at",
+	tasks.FilterUserID:      "user_id",
+	tasks.FilterTeamID:      "team_id",
+	tasks.FilterParentID:    "parent_id",
+}
+
+// turns a parsed filter into a parameterised WHERE condition. only whitelisted
+// column names and operators end up in the SQL; every value is a parameter,
+// numbered from argID on.
+type filterCompiler struct {
+	args  []interface{}
+	argID int
+}
+
+func compileFilter(expr tasks.FilterExpr, argID int) (string, []interface{}, error) {
+	c := &filterCompiler{argID: argID}
+	sql, err := c.compile(expr)
+	if err != nil {
+		return "", nil, err
+	}
+	return sql, c.args, nil
+}
+
+func (c *filterCompiler) param(v interface{}) string {
+	c.args = append(c.args, v)
+	c.argID++
+	return fmt.Sprintf("$%d", c.argID-1)
+}
+
+func (c *filterCompiler) compile(expr tasks.FilterExpr) (string, error) {
+	switch e := expr.(type) {
+	case *tasks.FilterAnd:
+		return c.binary(e.Left, "AND", e.Right)
+	case *tasks.FilterOr:
+		return c.binary(e.Left, "OR", e.Right)
+	case *tasks.FilterNot:
+		inner, err := c.compile(e.Expr)
+		if err != nil {
+			return "", err
+		}
+		return "NOT " + inner, nil
+	case *tasks.FilterComparison:
+		return c.compileComparison(e)
+	}
+	return "", fmt.Errorf("%w: unsupported expression %T", tasks.ErrInvalidFilter, expr)
+}
+
+func (c *filterCompiler) binary(left tasks.FilterExpr, op string, right tasks.FilterExpr) (string, error) {
+	l, err := c.compile(left)
+	if err != nil {
+		return "", err
+	}
+	r, err := c.compile(right)
+	if err != nil {
+		return "", err
+	}
+	return "(" + l + " " + op + " " + r + ")", nil
+}
+
+func (c *filterCompiler) compileComparison(cmp *tasks.FilterComparison) (string, error) {
+	switch cmp.Field {
+	case tasks.FilterAssignee, tasks.FilterWatcher:
+		relation := tasks.RelationAssignee
+		if cmp.Field == tasks.FilterWatcher {
+			relation = tasks.RelationWatcher
+		}
+		return c.exists(cmp, fmt.Sprintf(
+			"SELECT 1 FROM task_people p WHERE p.task_id = tasks.id AND p.relation = '%s' AND p.user_id = ANY(%%s)", relation))
+	case tasks.FilterLabel:
+		return c.exists(cmp,
+			"SELECT 1 FROM task_labels tl JOIN labels l ON l.id = tl.label_id WHERE tl.task_id = tasks.id AND lower(l.name) = ANY(%s)")
+	}
+
+	column, ok := filterColumns[cmp.Field]
+	if !ok {
+		return "", fmt.Errorf("%w: unknown field %q", tasks.ErrInvalidFilter, cmp.Field)
+	}
+	if cmp.Op == tasks.FilterIn {
+		return fmt.Sprintf("%s = ANY(%s)", column, c.param(filterArray(cmp.Field, cmp.Values))), nil
+	}
+
+	v := cmp.Values[0]
+	switch {
+	case v.Null && cmp.Op == tasks.FilterEq:
+		return column + " IS NULL", nil
+	case v.Null && cmp.Op == tasks.FilterNe:
+		return column + " IS NOT NULL", nil
+	case cmp.Op == tasks.FilterContains:
+		return fmt.Sprintf("%s ILIKE '%%' || %s || '%%'", column, c.param(escapeLike(v.Text))), nil
+	case cmp.Op == tasks.FilterNe:
+		// unlike !=, this keeps rows where the column is null
+		return fmt.Sprintf("%s IS DISTINCT FROM %s", column, c.param(filterScalar(cmp.Field, v))), nil
+	}
+	switch cmp.Op {
+	case tasks.FilterEq, tasks.FilterLt, tasks.FilterLe, tasks.FilterGt, tasks.FilterGe:
+		return fmt.Sprintf("%s %s %s", column, cmp.Op, c.param(filterScalar(cmp.Field, v))), nil
+	}
+	return "", fmt.Errorf("%w: unsupported operator %q", tasks.ErrInvalidFilter, cmp.Op)
+}
+
+// = and in become EXISTS over the given subquery, != becomes NOT EXISTS
+func (c *filterCompiler) exists(cmp *tasks.FilterComparison, subquery string) (string, error) {
+	sql := "EXISTS (" + fmt.Sprintf(subquery, c.param(filterArray(cmp.Field, cmp.Values))) + ")"
+	if cmp.Op == tasks.FilterNe {
+		return "NOT " + sql, nil
+	}
+	return sql, nil
+}
+
+func filterScalar(field tasks.FilterField, v tasks.FilterValue) interface{} {
+	switch field {
+	case tasks.FilterCreatedAt, tasks.FilterUpdatedAt, tasks.FilterDueAt:
+		return v.Time
+	case tasks.FilterUserID, tasks.FilterTeamID, tasks.FilterParentID:
+		return v.Int
+	}
+	return v.Text
+}
+
+// the values of an in list, or of a comparison against a subquery
+func filterArray(field tasks.FilterField, values []tasks.FilterValue) interface{} {
+	if _, ok := filterScalar(field, tasks.FilterValue{}).(string); ok {
+		texts := make([]string, len(values))
+		for i, v := range values {
+			texts[i] = v.Text
+		}
+		return pq.Array(texts)
+	}
+	ids := make([]int64, len(values))
+	for i, v := range values {
+		ids[i] = int64(v.Int)
+	}
+	return pq.Array(ids)
+}
+
+// makes % and _ in user input match literally in ILIKE
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,77 @@
+package persistance
+
+import (
+	"context"
+	"fmt"
+	"task_service/internal/core/tasks"
+)
+
+// arbitrary but fixed advisory lock key, held while a task is moved in the hierarchy
+const hierarchyLockKey int64 = 7_262_917_003
+
+// serializes parent changes for the current transaction. without it two concurrent
+// moves could each pass the cycle check and still form a loop together.
+func (store *DBStore) LockTaskHierarchy(ctx context.Context) error {
+	if _, err := store.conn(ctx).ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, hierarchyLockKey); err != nil {
+		return fmt.Errorf("could not lock task hierarchy: %w", err)
+	}
+	return nil
+}
+
+// ids of the task's parent, grandparent and so on up to the root
+func (store *DBStore) ListAncestorIDs(ctx context.Context, id int) ([]int, error) {
+	query := `WITH RECURSIVE ancestors (id, depth) AS (
+			SELECT parent_id, 1 FROM tasks WHERE id = $1 AND parent_id IS NOT NULL
+			UNION ALL
+			SELECT t.parent_id, a.depth + 1 FROM tasks t JOIN ancestors a ON t.id = a.id WHERE t.parent_id IS NOT NULL
+		)
+		SELECT id FROM ancestors ORDER BY depth`
+	rows, err := store.conn(ctx).QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("could not query ancestors: %w", err)
+	}
+	defer rows.Close()
+	var ids []int
+	for rows.Next() {
+		var ancestorID int
+		if err := rows.Scan(&ancestorID); err != nil {
+			return nil, fmt.Errorf("could not scan ancestor row: %w", err)
+		}
+		ids = append(ids, ancestorID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating ancestor rows: %w", err)
+	}
+	return ids, nil
+}
+
+// moves the task under parentID, or to the top level when parentID is nil.
+// setting the parent it already has leaves the task, and its version, alone.
+func (store *DBStore) SetTaskParent(ctx context.Context, id int, parentID *int) error {
+	query := `UPDATE tasks SET parent_id = $1,
+			updated_at = CASE WHEN parent_id IS DISTINCT FROM $1 THEN now() ELSE updated_at END,
+			version = CASE WHEN parent_id IS DISTINCT FROM $1 THEN version + 1 ELSE version END
+		WHERE id = $2`
+	res, err := store.conn(ctx).ExecContext(ctx, query, parentID, id)
+	if err != nil {
+		return fmt.Errorf("could not set parent: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("could not set parent: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("task with id %d: %w", id, tasks.ErrTaskNotFound)
+	}
+	return nil
+}
+
+// number of direct subtasks that are neither done nor cancelled
+func (store *DBStore) CountOpenSubtasks(ctx context.Context, id int) (int, error) {
+	query := `SELECT count(*) FROM tasks WHERE parent_id = $1 AND status NOT IN ($2, $3)`
+	var count int
+	if err := store.conn(ctx).QueryRowContext(ctx, query, id, tasks.StatusDone, tasks.StatusCancelled).Scan(&count); err != nil {
+		return 0, fmt.Errorf("could not count subtasks: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,143 @@
+package persistance
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"task_service/internal/core/labels"
+
+	"github.com/lib/pq"
+)
+
+const labelColumns = `id, name, color, owner_id, team_id, created_at`
+
+func scanLabel(row rowScanner, label *labels.Label) error {
+	var ownerID, teamID sql.NullInt64
+	var createdAt sql.NullTime
+	if err := row.Scan(&label.ID, &label.Name, &label.Color, &ownerID, &teamID, &createdAt); err != nil {
+		return err
+	}
+	label.OwnerID, label.TeamID, label.CreatedAt = nil, nil, nil
+	if ownerID.Valid {
+		id := int(ownerID.Int64)
+		label.OwnerID = &id
+	}
+	if teamID.Valid {
+		id := int(teamID.Int64)
+		label.TeamID = &id
+	}
+	if createdAt.Valid {
+		label.CreatedAt = &createdAt.Time
+	}
+	return nil
+}
+
+// unique_violation, e.g. on the per-owner or per-team name indexes of labels and views
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+func (store *DBStore) CreateLabel(ctx context.Context, label *labels.Label) error {
+	query := `INSERT INTO labels (name, color, owner_id, team_id) VALUES ($1, $2, $3, $4) RETURNING ` + labelColumns
+	err := scanLabel(store.conn(ctx).QueryRowContext(ctx, query, label.Name, label.Color, label.OwnerID, label.TeamID), label)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("label %q: %w", label.Name, labels.ErrDuplicateLabel)
+	}
+	if err != nil {
+		return fmt.Errorf("could not create label: %w", err)
+	}
+	return nil
+}
+
+func (store *DBStore) GetLabel(ctx context.Context, id int) (*labels.Label, error) {
+	query := `SELECT ` + labelColumns + ` FROM labels WHERE id = $1`
+	label := &labels.Label{}
+	if err := scanLabel(store.conn(ctx).QueryRowContext(ctx, query, id), label); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("label with id %d: %w", id, labels.ErrLabelNotFound)
+		}
+		return nil, fmt.Errorf("could not get label: %w", err)
+	}
+	return label, nil
+}
+
+// the labels of a user, or of a team when teamID is set, by name
+func (store *DBStore) ListLabels(ctx context.Context, ownerID, teamID int) ([]labels.Label, error) {
+	query := `SELECT ` + labelColumns + ` FROM labels WHERE owner_id = $1 ORDER BY lower(name)`
+	arg := ownerID
+	if teamID != 0 {
+		query = `SELECT ` + labelColumns + ` FROM labels WHERE team_id = $1 ORDER BY lower(name)`
+		arg = teamID
+	}
+	rows, err := store.conn(ctx).QueryContext(ctx, query, arg)
+	if err != nil {
+		return nil, fmt.Errorf("could not query labels: %w", err)
+	}
+	defer rows.Close()
+	labelList := []labels.Label{}
+	for rows.Next() {
+		var label labels.Label
+		if err := scanLabel(rows, &label); err != nil {
+			return nil, fmt.Errorf("could not scan label row: %w", err)
+		}
+		labelList = append(labelList, label)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating label rows: %w", err)
+	}
+	return labelList, nil
+}
+
+// renames and recolors a label
+func (store *DBStore) UpdateLabel(ctx context.Context, label *labels.Label) error {
+	query := `UPDATE labels SET name = $1, color = $2 WHERE id = $3 RETURNING ` + labelColumns
+	err := scanLabel(store.conn(ctx).QueryRowContext(ctx, query, label.Name, label.Color, label.ID), label)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("label with id %d: %w", label.ID, labels.ErrLabelNotFound)
+	}
+	if isUniqueViolation(err) {
+		return fmt.Errorf("label %q: %w", label.Name, labels.ErrDuplicateLabel)
+	}
+	if err != nil {
+		return fmt.Errorf("could not update label: %w", err)
+	}
+	return nil
+}
+
+// deletes the label and detaches it from every task
+func (store *DBStore) DeleteLabel(ctx context.Context, id int) error {
+	res, err := store.conn(ctx).ExecContext(ctx, `DELETE FROM labels WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("could not delete label: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("could not delete label: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("label with id %d: %w", id, labels.ErrLabelNotFound)
+	}
+	return nil
+}
+
+// attaching a label twice is not an error
+func (store *DBStore) AttachLabel(ctx context.Context, taskID, labelID int) error {
+	query := `INSERT INTO task_labels (task_id, label_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+	res, err := store.conn(ctx).ExecContext(ctx, query, taskID, labelID)
+	if err != nil {
+		return fmt.Errorf("could not attach label: %w", err)
+	}
+	return store.touchTask(ctx, taskID, res)
+}
+
+// detaching a label that isn't attached is not an error
+func (store *DBStore) DetachLabel(ctx context.Context, taskID, labelID int) error {
+	query := `DELETE FROM task_labels WHERE task_id = $1 AND label_id = $2`
+	res, err := store.conn(ctx).ExecContext(ctx, query, taskID, labelID)
+	if err != nil {
+		return fmt.Errorf("could not detach label: %w", err)
+	}
+	return store.touchTask(ctx, taskID, res)
+}
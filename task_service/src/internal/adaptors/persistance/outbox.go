@@ -0,0 +1,94 @@
+package persistance
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"shared/events"
+	"task_service/internal/core/outbox"
+	"time"
+)
+
+// arbitrary but fixed advisory lock key, held by whichever relay is draining the outbox
+const outboxLockKey int64 = 7_262_917_002
+
+// stores an event in the outbox. called inside the same transaction as the task write.
+func (store *DBStore) EnqueueEvent(ctx context.Context, event *events.Event) error {
+	payload, err := events.Encode(event)
+	if err != nil {
+		return err
+	}
+	query := `INSERT INTO task_outbox (event_id, task_id, event_type, payload) VALUES ($1, $2, $3, $4)`
+	if _, err := store.conn(ctx).ExecContext(ctx, query, event.ID, event.Task.ID, event.Type, string(payload)); err != nil {
+		return fmt.Errorf("could not enqueue event: %w", err)
+	}
+	return nil
+}
+
+// takes the relay lock for the current transaction. false means another relay holds it.
+func (store *DBStore) TryLockOutbox(ctx context.Context) (bool, error) {
+	var locked bool
+	if err := store.conn(ctx).QueryRowContext(ctx, `SELECT pg_try_advisory_xact_lock($1)`, outboxLockKey).Scan(&locked); err != nil {
+		return false, fmt.Errorf("could not lock outbox: %w", err)
+	}
+	return locked, nil
+}
+
+// the oldest unpublished record of each task, if it is due. a later record of
+// a task is never returned while an earlier one is still waiting, which keeps
+// events in order per task.
+func (store *DBStore) NextOutboxBatch(ctx context.Context, limit int) ([]outbox.Record, error) {
+	query := `SELECT o.id, o.event_id, o.task_id, o.event_type, o.payload, o.attempts, o.created_at, o.next_attempt_at
+		FROM task_outbox o
+		WHERE o.next_attempt_at <= now()
+		AND NOT EXISTS (SELECT 1 FROM task_outbox earlier WHERE earlier.task_id = o.task_id AND earlier.id < o.id)
+		ORDER BY o.id
+		LIMIT $1`
+	rows, err := store.conn(ctx).QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("could not query outbox: %w", err)
+	}
+	defer rows.Close()
+	var records []outbox.Record
+	for rows.Next() {
+		var r outbox.Record
+		if err := rows.Scan(&r.ID, &r.EventID, &r.TaskID, &r.EventType, &r.Payload, &r.Attempts, &r.CreatedAt, &r.NextAttemptAt); err != nil {
+			return nil, fmt.Errorf("could not scan outbox row: %w", err)
+		}
+		records = append(records, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating outbox rows: %w", err)
+	}
+	return records, nil
+}
+
+// removes a record once it has been published
+func (store *DBStore) DeleteOutboxRecord(ctx context.Context, id int64) error {
+	if _, err := store.conn(ctx).ExecContext(ctx, `DELETE FROM task_outbox WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("could not delete outbox record: %w", err)
+	}
+	return nil
+}
+
+// records a failed publish attempt and when to try again
+func (store *DBStore) RescheduleOutboxRecord(ctx context.Context, id int64, nextAttemptAt time.Time, lastError string) error {
+	query := `UPDATE task_outbox SET attempts = attempts + 1, next_attempt_at = $2, last_error = $3 WHERE id = $1`
+	if _, err := store.conn(ctx).ExecContext(ctx, query, id, nextAttemptAt, lastError); err != nil {
+		return fmt.Errorf("could not reschedule outbox record: %w", err)
+	}
+	return nil
+}
+
+func (store *DBStore) OutboxBacklog(ctx context.Context) (outbox.Backlog, error) {
+	var backlog outbox.Backlog
+	var oldest sql.NullTime
+	err := store.conn(ctx).QueryRowContext(ctx, `SELECT count(*), min(created_at) FROM task_outbox`).Scan(&backlog.Size, &oldest)
+	if err != nil {
+		return backlog, fmt.Errorf("could not read outbox backlog: %w", err)
+	}
+	if oldest.Valid {
+		backlog.Oldest = &oldest.Time
+	}
+	return backlog, nil
+}
@@ -0,0 +1,48 @@
+package persistance
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"task_service/internal/core/tasks"
+
+	"github.com/lib/pq"
+)
+
+// attaches the users to the task. users already attached that way are left alone.
+func (store *DBStore) AddTaskPeople(ctx context.Context, taskID int, relation tasks.Relation, userIDs []int) error {
+	query := `INSERT INTO task_people (task_id, user_id, relation)
+		SELECT $1, unnest($2::int[]), $3 ON CONFLICT DO NOTHING`
+	res, err := store.conn(ctx).ExecContext(ctx, query, taskID, pq.Array(userIDs), relation)
+	if err != nil {
+		return fmt.Errorf("could not add %ss: %w", relation, err)
+	}
+	return store.touchTask(ctx, taskID, res)
+}
+
+// detaches the user from the task; removing someone who isn't attached is not an error
+func (store *DBStore) RemoveTaskPerson(ctx context.Context, taskID int, relation tasks.Relation, userID int) error {
+	query := `DELETE FROM task_people WHERE task_id = $1 AND relation = $2 AND user_id = $3`
+	res, err := store.conn(ctx).ExecContext(ctx, query, taskID, relation, userID)
+	if err != nil {
+		return fmt.Errorf("could not remove %s: %w", relation, err)
+	}
+	return store.touchTask(ctx, taskID, res)
+}
+
+// people, labels and dependencies are part of the task, so changing them counts
+// as an update. res is the result of the change: when it affected no rows, e.g.
+// adding someone already attached, nothing changed and the version stays.
+func (store *DBStore) touchTask(ctx context.Context, taskID int, res sql.Result) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("could not update task: %w", err)
+	}
+	if affected == 0 {
+		return nil
+	}
+	if _, err := store.conn(ctx).ExecContext(ctx, `UPDATE tasks SET updated_at = now(), version = version + 1 WHERE id = $1`, taskID); err != nil {
+		return fmt.Errorf("could not update task: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package persistance
+
+import (
+	"fmt"
+	"strings"
+	"task_service/internal/core/tasks"
+)
+
+// text search configuration used by the search_vector column
+const searchConfig = "english"
+
+// ranks search results; title matches weigh more than description matches
+const searchRank = "ts_rank_cd(search_vector, search.query)"
+
+// extra columns selected for search results, in the order scanTask's extra destinations expect.
+// the text is HTML-escaped before highlighting, so the <mark> tags are the only markup in it.
+var searchColumns = searchRank + ", " +
+	"ts_headline('" + searchConfig + "', " + htmlEscaped("title") + ", search.query, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true'), " +
+	"ts_headline('" + searchConfig + "', " + htmlEscaped("description") + ", search.query, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2')"
+
+// an SQL expression escaping the column for HTML. the text search parser reads
+// each entity as a single token, so the words around it still match.
+func htmlEscaped(column string) string {
+	expr := column
+	// & goes first, so the other entities aren't escaped again
+	for _, r := range [][2]string{{"&", "&amp;"}, {"<", "&lt;"}, {">", "&gt;"}, {`"`, "&quot;"}, {"''", "&#39;"}} {
+		expr = fmt.Sprintf("replace(%s, '%s', '%s')", expr, r[0], r[1])
+	}
+	return expr
+}
+
+// builds a tsquery expression matching every term. user input only ever reaches
+// postgres as parameters, starting at $argID; the returned args fill them in order.
+func searchQuery(terms []tasks.SearchTerm, argID int) (string, []interface{}) {
+	parts := make([]string, 0, len(terms))
+	args := make([]interface{}, 0, len(terms))
+	for _, term := range terms {
+		text := term.Text
+		switch {
+		case term.Phrase:
+			parts = append(parts, fmt.Sprintf("phraseto_tsquery('%s', $%d)", searchConfig, argID))
+		case term.Prefix:
+			parts = append(parts, fmt.Sprintf("to_tsquery('%s', $%d)", searchConfig, argID))
+			text = prefixOperand(text)
+		default:
+			parts = append(parts, fmt.Sprintf("plainto_tsquery('%s', $%d)", searchConfig, argID))
+		}
+		args = append(args, text)
+		argID++
+	}
+	return "(" + strings.Join(parts, " && ") + ")", args
+}
+
+// quotes a word as a single tsquery operand, whatever characters it holds, and
+// marks it as a prefix. inside the quotes a backslash escapes the next character
+// and a doubled quote is a quote. escaped here rather than with quote_literal,
+// whose E'...' strings to_tsquery doesn't understand.
+func prefixOperand(word string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `''`).Replace(word) + "':*"
+}
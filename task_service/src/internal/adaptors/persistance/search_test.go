@@ -0,0 +1,49 @@
+package persistance
+
+import (
+	"reflect"
+	"task_service/internal/core/tasks"
+	"testing"
+)
+
+func TestPrefixOperand(t *testing.T) {
+	tests := []struct {
+		name, word, want string
+	}{
+		{"plain word", `auth`, `'auth':*`},
+		{"single quote is doubled", `o'clock`, `'o''clock':*`},
+		{"only quotes", `''`, `'''''':*`},
+		{"backslash is escaped", `C:\temp`, `'C:\\temp':*`},
+		{"trailing backslash can't escape the closing quote", `a\`, `'a\\':*`},
+		{"backslash before a quote", `\'`, `'\\''':*`},
+		{"tsquery operators stay inside the quotes", `a&b|!c:*`, `'a&b|!c:*':*`},
+		{"star", `a*b`, `'a*b':*`},
+		{"non-ASCII", `überprüf`, `'überprüf':*`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := prefixOperand(tt.word); got != tt.want {
+				t.Errorf("prefixOperand(%q) = %s, want %s", tt.word, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchQuery(t *testing.T) {
+	terms := []tasks.SearchTerm{
+		{Text: "deploy"},
+		{Text: "release notes", Phrase: true},
+		{Text: "it's", Prefix: true},
+	}
+	query, args := searchQuery(terms, 3)
+	wantQuery := "(plainto_tsquery('" + searchConfig + "', $3) && " +
+		"phraseto_tsquery('" + searchConfig + "', $4) && " +
+		"to_tsquery('" + searchConfig + "', $5))"
+	if query != wantQuery {
+		t.Errorf("query = %s, want %s", query, wantQuery)
+	}
+	wantArgs := []interface{}{"deploy", "release notes", `'it''s':*`}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("args = %v, want %v", args, wantArgs)
+	}
+}
@@ -0,0 +1,46 @@
+package persistance
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// the query methods shared by *sql.DB and *sql.Tx
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+type txContextKey struct{}
+
+// runs fn inside a transaction. store calls made with the ctx handed to fn
+// join the transaction; it commits if fn returns nil and rolls back otherwise.
+func (store *DBStore) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txContextKey{}).(*sql.Tx); ok {
+		return fn(ctx) // already inside a transaction
+	}
+
+	tx, err := store.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(context.WithValue(ctx, txContextKey{}, tx)); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit transaction: %w", err)
+	}
+	return nil
+}
+
+// the transaction carried by ctx, or the plain connection pool
+func (store *DBStore) conn(ctx context.Context) dbtx {
+	if tx, ok := ctx.Value(txContextKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return store.DB
+}
@@ -0,0 +1,158 @@
+package persistance
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"task_service/internal/core/views"
+
+	"github.com/lib/pq"
+)
+
+// the columns scanned by scanView. %d is the parameter holding the user whose
+// default view is_default refers to.
+const viewColumns = `id, name, filter, sort, columns, owner_id, team_id, created_by,
+	EXISTS (SELECT 1 FROM default_views d WHERE d.view_id = views.id AND d.user_id = $%d),
+	created_at, updated_at`
+
+func selectViewColumns(userArg int) string {
+	return fmt.Sprintf(viewColumns, userArg)
+}
+
+func scanView(row rowScanner, view *views.View) error {
+	var ownerID, teamID sql.NullInt64
+	if err := row.Scan(&view.ID, &view.Name, &view.Filter, &view.Sort, pq.Array(&view.Columns), &ownerID, &teamID,
+		&view.CreatedBy, &view.IsDefault, &view.CreatedAt, &view.UpdatedAt); err != nil {
+		return err
+	}
+	view.OwnerID, view.TeamID = nil, nil
+	if ownerID.Valid {
+		id := int(ownerID.Int64)
+		view.OwnerID = &id
+	}
+	if teamID.Valid {
+		id := int(teamID.Int64)
+		view.TeamID = &id
+	}
+	return nil
+}
+
+func (store *DBStore) CreateView(ctx context.Context, view *views.View) error {
+	query := `INSERT INTO views (name, filter, sort, columns, owner_id, team_id, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING ` + selectViewColumns(7)
+	err := scanView(store.conn(ctx).QueryRowContext(ctx, query,
+		view.Name, view.Filter, view.Sort, pq.Array(view.Columns), view.OwnerID, view.TeamID, view.CreatedBy), view)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("view %q: %w", view.Name, views.ErrDuplicateView)
+	}
+	if err != nil {
+		return fmt.Errorf("could not create view: %w", err)
+	}
+	return nil
+}
+
+// IsDefault is set if the view is userID's default
+func (store *DBStore) GetView(ctx context.Context, id, userID int) (*views.View, error) {
+	query := `SELECT ` + selectViewColumns(2) + ` FROM views WHERE id = $1`
+	view := &views.View{}
+	if err := scanView(store.conn(ctx).QueryRowContext(ctx, query, id, userID), view); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("view with id %d: %w", id, views.ErrViewNotFound)
+		}
+		return nil, fmt.Errorf("could not get view: %w", err)
+	}
+	return view, nil
+}
+
+// the personal views of userID and the views of teamIDs, by name
+func (store *DBStore) ListViews(ctx context.Context, userID int, teamIDs []int) ([]views.View, error) {
+	query := `SELECT ` + selectViewColumns(1) + ` FROM views
+		WHERE owner_id = $1 OR team_id = ANY($2)
+		ORDER BY lower(name), id`
+	ids := make([]int64, len(teamIDs))
+	for i, id := range teamIDs {
+		ids[i] = int64(id)
+	}
+	rows, err := store.conn(ctx).QueryContext(ctx, query, userID, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("could not query views: %w", err)
+	}
+	defer rows.Close()
+	viewList := []views.View{}
+	for rows.Next() {
+		var view views.View
+		if err := scanView(rows, &view); err != nil {
+			return nil, fmt.Errorf("could not scan view row: %w", err)
+		}
+		viewList = append(viewList, view)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating view rows: %w", err)
+	}
+	return viewList, nil
+}
+
+// saves the name, filter, sort and columns. IsDefault is reloaded for userID.
+func (store *DBStore) UpdateView(ctx context.Context, view *views.View, userID int) error {
+	query := `UPDATE views SET name = $1, filter = $2, sort = $3, columns = $4, updated_at = now()
+		WHERE id = $5 RETURNING ` + selectViewColumns(6)
+	err := scanView(store.conn(ctx).QueryRowContext(ctx, query,
+		view.Name, view.Filter, view.Sort, pq.Array(view.Columns), view.ID, userID), view)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("view with id %d: %w", view.ID, views.ErrViewNotFound)
+	}
+	if isUniqueViolation(err) {
+		return fmt.Errorf("view %q: %w", view.Name, views.ErrDuplicateView)
+	}
+	if err != nil {
+		return fmt.Errorf("could not update view: %w", err)
+	}
+	return nil
+}
+
+// deletes the view; users who had it as their default are left without one
+func (store *DBStore) DeleteView(ctx context.Context, id int) error {
+	res, err := store.conn(ctx).ExecContext(ctx, `DELETE FROM views WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("could not delete view: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("could not delete view: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("view with id %d: %w", id, views.ErrViewNotFound)
+	}
+	return nil
+}
+
+// replaces the user's default view, if they had one
+func (store *DBStore) SetDefaultView(ctx context.Context, userID, viewID int) error {
+	query := `INSERT INTO default_views (user_id, view_id) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET view_id = EXCLUDED.view_id`
+	if _, err := store.conn(ctx).ExecContext(ctx, query, userID, viewID); err != nil {
+		return fmt.Errorf("could not set default view: %w", err)
+	}
+	return nil
+}
+
+// clearing a default that isn't set is not an error
+func (store *DBStore) ClearDefaultView(ctx context.Context, userID int) error {
+	if _, err := store.conn(ctx).ExecContext(ctx, `DELETE FROM default_views WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("could not clear default view: %w", err)
+	}
+	return nil
+}
+
+// views.ErrViewNotFound if the user has no default view
+func (store *DBStore) GetDefaultViewID(ctx context.Context, userID int) (int, error) {
+	var id int
+	err := store.conn(ctx).QueryRowContext(ctx, `SELECT view_id FROM default_views WHERE user_id = $1`, userID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("user %d has no default view: %w", userID, views.ErrViewNotFound)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("could not get default view: %w", err)
+	}
+	return id, nil
+}
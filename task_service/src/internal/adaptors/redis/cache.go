@@ -3,6 +3,7 @@ package redis
 import (
 	"context"
 	"fmt"
+	"shared/events"
 	"time"
 
 	"github.com/go-redis/redis/v8"
@@ -24,36 +25,50 @@ func NewCache(address string) (*Cache, error) {
 	return &Cache{client: client}, nil
 }
 
-func (c *Cache) SetUserValidation(ctx context.Context, userID int32) error {
+// remembers that a user exists, and whether they verified their email
+func (c *Cache) SetUserValidation(ctx context.Context, userID int32, emailVerified bool) error {
 
 	key := fmt.Sprintf("user_validated:%d", userID)
-	err := c.client.Set(ctx, key, 1, 5*time.Minute).Err() // short expiration to periodically re-validate
+	value := 1
+	if emailVerified {
+		value = 2
+	}
+	err := c.client.Set(ctx, key, value, 5*time.Minute).Err() // short expiration to periodically re-validate
 	if err != nil {
 		return fmt.Errorf("could not set user validation in cache: %w", err)
 	}
 	return nil
 }
 
-// to check if a user ID is present in the cache
-func (c *Cache) GetUserValidation(ctx context.Context, userID int32) (bool, error) {
+// to check if a user ID is present in the cache, and whether it was cached as verified
+func (c *Cache) GetUserValidation(ctx context.Context, userID int32) (validated, emailVerified bool, err error) {
 	key := fmt.Sprintf("user_validated:%d", userID)
-	err := c.client.Get(ctx, key).Err()
+	value, err := c.client.Get(ctx, key).Int()
 
 	if err == redis.Nil {
-		return false, nil //cache miss only, not a real problem
+		return false, false, nil //cache miss only, not a real problem
 	} else if err != nil {
-		return false, fmt.Errorf("could not get user validation from cache: %w", err) // any other error should be a real problem.
+		return false, false, fmt.Errorf("could not get user validation from cache: %w", err) // any other error should be a real problem.
 	}
 
-	// If we are getting here, err was nil, meaning the key exists => cache hit
-	return true, nil
+	// If we are getting here, err was nil, meaning the key exists => cache hit.
+	// entries from before the verified flag was cached hold 1, i.e. not verified.
+	return true, value == 2, nil
 }
 
-// to send a message to the task_notifications channel.
-func (c *Cache) PublishTaskNotification(ctx context.Context, message string) error {
-	err := c.client.Publish(ctx, "task_notifications", message).Err()
+// roughly how many entries the task events stream keeps; consumers ack long before that
+const taskEventsStreamMaxLen = 100_000
+
+// to append an already encoded task event to the task events stream.
+func (c *Cache) PublishTaskEvent(ctx context.Context, payload []byte) error {
+	err := c.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: events.TaskEventsStream,
+		MaxLen: taskEventsStreamMaxLen,
+		Approx: true,
+		Values: map[string]interface{}{events.PayloadField: payload},
+	}).Err()
 	if err != nil {
-		return fmt.Errorf("could not publish task notification: %w", err)
+		return fmt.Errorf("could not publish task event: %w", err)
 	}
 	return nil
 }
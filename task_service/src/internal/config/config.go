@@ -1,7 +1,9 @@
 package config
 
 import (
+	"fmt"
 	"log"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -12,6 +14,16 @@ type Config struct {
 	ServerAddress          string `mapstructure:"SERVER_ADDRESS"`
 	UserServiceGRPCAddress string `mapstructure:"USER_SERVICE_GRPC_ADDRESS"`
 	RedisAddress           string `mapstructure:"REDIS_ADDRESS"`
+	JWTSecretKey           string `mapstructure:"JWT_SECRET_KEY"`
+	DebugAddress           string `mapstructure:"DEBUG_ADDRESS"` // internal listener for /debug/vars; empty turns it off
+
+	OutboxPollInterval time.Duration `mapstructure:"OUTBOX_POLL_INTERVAL"`
+	OutboxBatchSize    int           `mapstructure:"OUTBOX_BATCH_SIZE"`
+	OutboxMinBackoff   time.Duration `mapstructure:"OUTBOX_MIN_BACKOFF"`
+	OutboxMaxBackoff   time.Duration `mapstructure:"OUTBOX_MAX_BACKOFF"`
+
+	RequireVerifiedUsers  bool `mapstructure:"REQUIRE_VERIFIED_USERS"`  // refuse to assign tasks to users with unverified emails
+	RequireClosedSubtasks bool `mapstructure:"REQUIRE_CLOSED_SUBTASKS"` // refuse to mark a task done while subtasks are open
 }
 
 func LoadConfig(path string) (config Config, err error) {
@@ -20,6 +32,15 @@ func LoadConfig(path string) (config Config, err error) {
 	viper.SetConfigType("env")
 	viper.AutomaticEnv()
 
+	// defaults also make viper pick these keys up from the environment
+	viper.SetDefault("DEBUG_ADDRESS", "localhost:6060")
+	viper.SetDefault("OUTBOX_POLL_INTERVAL", time.Second)
+	viper.SetDefault("OUTBOX_BATCH_SIZE", 100)
+	viper.SetDefault("OUTBOX_MIN_BACKOFF", time.Second)
+	viper.SetDefault("OUTBOX_MAX_BACKOFF", 5*time.Minute)
+	viper.SetDefault("REQUIRE_VERIFIED_USERS", false)
+	viper.SetDefault("REQUIRE_CLOSED_SUBTASKS", true)
+
 	err = viper.ReadInConfig()
 	if err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
@@ -29,6 +50,30 @@ func LoadConfig(path string) (config Config, err error) {
 		}
 	}
 
-	err = viper.Unmarshal(&config)
+	if err = viper.Unmarshal(&config); err != nil {
+		return
+	}
+	err = config.validate()
 	return
 }
+
+// HS256 keys shorter than this can be brute-forced from a single token
+const MinJWTSecretLength = 32
+
+// rejects settings the service can't safely run with
+func (c Config) validate() error {
+	if len(c.JWTSecretKey) < MinJWTSecretLength {
+		return fmt.Errorf("JWT_SECRET_KEY must be at least %d bytes, got %d", MinJWTSecretLength, len(c.JWTSecretKey))
+	}
+	if c.OutboxPollInterval <= 0 {
+		return fmt.Errorf("OUTBOX_POLL_INTERVAL must be positive, got %s", c.OutboxPollInterval)
+	}
+	if c.OutboxBatchSize <= 0 {
+		return fmt.Errorf("OUTBOX_BATCH_SIZE must be positive, got %d", c.OutboxBatchSize)
+	}
+	if c.OutboxMinBackoff <= 0 || c.OutboxMaxBackoff < c.OutboxMinBackoff {
+		return fmt.Errorf("OUTBOX_MIN_BACKOFF must be positive and at most OUTBOX_MAX_BACKOFF, got %s and %s",
+			c.OutboxMinBackoff, c.OutboxMaxBackoff)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package comments
+
+import (
+	"encoding/base64"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	ErrCommentNotFound = errors.New("comment not found")
+	ErrEmptyComment    = errors.New("comment body is empty")
+	ErrCommentTooLong  = errors.New("comment body is too long")
+	ErrInvalidCursor   = errors.New("invalid cursor")
+)
+
+const (
+	MaxBodyLength   = 10000 // in characters
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+	MaxMentions     = 50 // distinct usernames resolved per comment; later ones stay plain text
+)
+
+// a comment on a task. deleted comments are kept in the database but never returned.
+type Comment struct {
+	ID        int       `json:"id"`
+	TaskID    int       `json:"task_id"`
+	AuthorID  int       `json:"author_id"`
+	Body      string    `json:"body"`
+	Mentions  []int     `json:"mentions"` // ids of the users @mentioned in the body
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// trims the body and checks it is neither empty nor too long
+func NormalizeBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", ErrEmptyComment
+	}
+	if utf8.RuneCountInString(body) > MaxBodyLength {
+		return "", ErrCommentTooLong
+	}
+	return body, nil
+}
+
+// an @ that starts a word, followed by the username
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([\w.-]+)`)
+
+// usernames mentioned as @username, each listed once in order of appearance.
+// trailing dots are dropped so "thanks @alice." mentions alice.
+// at most MaxMentions names are returned.
+func ParseMentions(body string) []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, m := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		name := strings.ToLower(strings.TrimRight(m[1], "."))
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+			if len(names) == MaxMentions {
+				break
+			}
+		}
+	}
+	return names
+}
+
+// criteria for listing a task's comments, oldest first
+type ListFilter struct {
+	TaskID  int
+	AfterID int // exclusive; decoded from the cursor
+	Limit   int
+}
+
+// one page of comments
+type CommentPage struct {
+	Comments   []Comment `json:"comments"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+}
+
+// the cursor pointing just after the comment
+func EncodeCursor(c Comment) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(c.ID)))
+}
+
+// returns the id of the last comment of the previous page
+func DecodeCursor(raw string) (int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+	id, err := strconv.Atoi(string(data))
+	if err != nil || id < 0 {
+		return 0, ErrInvalidCursor
+	}
+	return id, nil
+}
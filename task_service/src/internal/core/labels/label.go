@@ -0,0 +1,57 @@
+package labels
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	ErrLabelNotFound    = errors.New("label not found")
+	ErrInvalidLabelName = errors.New("invalid label name")
+	ErrInvalidColor     = errors.New("invalid label color")
+	ErrDuplicateLabel   = errors.New("a label with this name already exists")
+	ErrLabelNotUsable   = errors.New("label can't be used on this task")
+)
+
+const (
+	MaxNameLength = 50
+	DefaultColor  = "#808080"
+)
+
+// a label belongs either to a single user (OwnerID) or to a team (TeamID), never both.
+// names are unique within that scope, ignoring case.
+type Label struct {
+	ID        int        `json:"id"`
+	Name      string     `json:"name"`
+	Color     string     `json:"color"` // #rrggbb
+	OwnerID   *int       `json:"owner_id,omitempty"`
+	TeamID    *int       `json:"team_id,omitempty"`
+	CreatedAt *time.Time `json:"created_at,omitempty"` // not set on labels embedded in a task
+}
+
+// trims the name and checks its length. names can't contain commas, which separate labels in queries.
+func NormalizeName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > MaxNameLength || strings.Contains(name, ",") {
+		return "", fmt.Errorf("%w: %q must be 1 to %d characters without commas", ErrInvalidLabelName, name, MaxNameLength)
+	}
+	return name, nil
+}
+
+var colorPattern = regexp.MustCompile(`^#[0-9a-f]{6}$`)
+
+// accepts #rrggbb in either case and returns it lower cased. empty input gives DefaultColor.
+func ParseColor(color string) (string, error) {
+	if color == "" {
+		return DefaultColor, nil
+	}
+	color = strings.ToLower(color)
+	if !colorPattern.MatchString(color) {
+		return "", fmt.Errorf("%w: %q is not #rrggbb", ErrInvalidColor, color)
+	}
+	return color, nil
+}
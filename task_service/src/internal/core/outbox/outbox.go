@@ -0,0 +1,21 @@
+package outbox
+
+import "time"
+
+// an encoded task event waiting in the outbox table to be relayed to the message bus
+type Record struct {
+	ID            int64
+	EventID       string
+	TaskID        int
+	EventType     string
+	Payload       []byte
+	Attempts      int
+	CreatedAt     time.Time
+	NextAttemptAt time.Time
+}
+
+// how far behind the relay is
+type Backlog struct {
+	Size   int
+	Oldest *time.Time // creation time of the oldest unpublished record, nil when empty
+}
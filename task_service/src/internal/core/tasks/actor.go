@@ -0,0 +1,42 @@
+package tasks
+
+// role names as issued in user_service tokens
+const (
+	RoleUser    = "user"
+	RoleManager = "manager"
+	RoleAdmin   = "admin"
+)
+
+// the authenticated user performing an operation, taken from the request's token.
+type Actor struct {
+	UserID int
+	Role   string
+}
+
+// reports whether the actor owns the task.
+func (a Actor) Owns(task *Task) bool {
+	return task.UserID == a.UserID
+}
+
+// managers and admins can see every task
+func (a Actor) CanReadAll() bool {
+	return a.Role == RoleManager || a.Role == RoleAdmin
+}
+
+// only admins can change or delete tasks they don't own
+func (a Actor) CanEditAll() bool {
+	return a.Role == RoleAdmin
+}
+
+func (a Actor) CanRead(task *Task) bool {
+	return a.CanEdit(task) || a.CanReadAll() || task.IsWatcher(a.UserID)
+}
+
+// assignees can work on the task, but not delete it
+func (a Actor) CanEdit(task *Task) bool {
+	return a.CanDelete(task) || task.IsAssignee(a.UserID)
+}
+
+func (a Actor) CanDelete(task *Task) bool {
+	return a.Owns(task) || a.CanEditAll()
+}
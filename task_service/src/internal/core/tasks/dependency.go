@@ -0,0 +1,13 @@
+package tasks
+
+// A task can depend on other tasks (its prerequisites). While any prerequisite
+// is open the task is blocked: it is moved to StatusBlocked automatically and
+// only moved back once every prerequisite is done or cancelled.
+
+// status an automatically blocked task returns to once it is unblocked
+func (t *Task) UnblockedStatus() Status {
+	if t.BlockedFrom != "" && StatusBlocked.CanTransitionTo(t.BlockedFrom) {
+		return t.BlockedFrom
+	}
+	return StatusPending
+}
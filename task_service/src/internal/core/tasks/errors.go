@@ -0,0 +1,32 @@
+package tasks
+
+import (
+	"errors"
+	"task_service/pkg/jsonpatch"
+)
+
+// domain errors, checked with errors.Is by the handlers to pick a status code.
+var (
+	ErrTaskNotFound            = errors.New("task not found")
+	ErrForbidden               = errors.New("forbidden")
+	ErrInvalidUser             = errors.New("invalid user ID")
+	ErrUnverifiedUser          = errors.New("user has not verified their email address")
+	ErrInvalidStatus           = errors.New("invalid status")
+	ErrInvalidStatusTransition = errors.New("invalid status transition")
+	ErrInvalidPriority         = errors.New("invalid priority")
+	ErrInvalidSort             = errors.New("invalid sort")
+	ErrInvalidCursor           = errors.New("invalid cursor")
+	ErrInvalidSearch           = errors.New("invalid search")
+	ErrInvalidFilter           = errors.New("invalid filter")
+	ErrInvalidParent           = errors.New("invalid parent task")
+	ErrTaskCycle               = errors.New("task cannot be its own ancestor")
+	ErrOpenSubtasks            = errors.New("task has open subtasks")
+	ErrInvalidDependency       = errors.New("invalid dependency")
+	ErrDependencyCycle         = errors.New("dependency would create a cycle")
+	ErrOpenDependencies        = errors.New("task has open prerequisites")
+	ErrVersionMismatch         = errors.New("task has been changed since it was read")
+	ErrInvalidTask             = errors.New("invalid task")
+	// the patch is malformed, or doesn't fit the task (a failed test, a missing path)
+	ErrInvalidPatch  = jsonpatch.ErrInvalidPatch
+	ErrPatchConflict = jsonpatch.ErrConflict
+)
@@ -0,0 +1,472 @@
+package tasks
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// The ?filter= language of GET /tasks. Examples:
+//
+//	status in (pending, blocked) and created_at > 2026-01-01 and not label:wontfix
+//	assignee = me or (watcher = me and priority = urgent)
+//	title ~ "release notes" and due_at != null
+//
+// Comparisons are combined with and, or, not and parentheses; and binds
+// tighter than or. Only the fields in filterFields can be used, each with its
+// own operators. label:x is short for label = x, and "me" stands for the caller
+// wherever a user id is expected. Keywords are case-insensitive.
+
+const (
+	MaxFilterLength      = 1000 // characters
+	MaxFilterComparisons = 50
+	maxFilterDepth       = 20 // nested parentheses and nots
+)
+
+// a filter expression: *FilterAnd, *FilterOr, *FilterNot or *FilterComparison
+type FilterExpr interface {
+	filterExpr()
+}
+
+type FilterAnd struct{ Left, Right FilterExpr }
+type FilterOr struct{ Left, Right FilterExpr }
+type FilterNot struct{ Expr FilterExpr }
+
+// a single field check. Values holds one value, or several for FilterIn.
+type FilterComparison struct {
+	Field  FilterField
+	Op     FilterOp
+	Values []FilterValue
+}
+
+func (*FilterAnd) filterExpr()        {}
+func (*FilterOr) filterExpr()         {}
+func (*FilterNot) filterExpr()        {}
+func (*FilterComparison) filterExpr() {}
+
+type FilterOp string
+
+const (
+	FilterEq       FilterOp = "="
+	FilterNe       FilterOp = "!="
+	FilterLt       FilterOp = "<"
+	FilterLe       FilterOp = "<="
+	FilterGt       FilterOp = ">"
+	FilterGe       FilterOp = ">="
+	FilterContains FilterOp = "~" // case-insensitive substring
+	FilterIn       FilterOp = "in"
+)
+
+// a value already checked against its field's type. exactly one of the fields is set,
+// unless Null is true.
+type FilterValue struct {
+	Null bool
+	Text string // status, priority, title, description and label values
+	Int  int
+	Time time.Time
+}
+
+type FilterField string
+
+const (
+	FilterStatus      FilterField = "status"
+	FilterPriority    FilterField = "priority"
+	FilterTitle       FilterField = "title"
+	FilterDescription FilterField = "description"
+	FilterCreatedAt   FilterField = "created_at"
+	FilterUpdatedAt   FilterField = "updated_at"
+	FilterDueAt       FilterField = "due_at"
+	FilterUserID      FilterField = "user_id"
+	FilterTeamID      FilterField = "team_id"
+	FilterParentID    FilterField = "parent_id"
+	FilterAssignee    FilterField = "assignee"
+	FilterWatcher     FilterField = "watcher"
+	FilterLabel       FilterField = "label"
+)
+
+type filterKind int
+
+const (
+	filterText filterKind = iota
+	filterStatusKind
+	filterPriorityKind
+	filterTime
+	filterUser
+)
+
+type filterFieldSpec struct {
+	kind     filterKind
+	ops      []FilterOp
+	nullable bool // can be compared with null using = and !=
+}
+
+// the whitelist: fields a filter may use and what can be done with them
+var filterFields = map[FilterField]filterFieldSpec{
+	FilterStatus:      {kind: filterStatusKind, ops: []FilterOp{FilterEq, FilterNe, FilterIn}},
+	FilterPriority:    {kind: filterPriorityKind, ops: []FilterOp{FilterEq, FilterNe, FilterIn}},
+	FilterTitle:       {kind: filterText, ops: []FilterOp{FilterEq, FilterNe, FilterContains}},
+	FilterDescription: {kind: filterText, ops: []FilterOp{FilterEq, FilterNe, FilterContains}},
+	FilterCreatedAt:   {kind: filterTime, ops: []FilterOp{FilterLt, FilterLe, FilterGt, FilterGe}},
+	FilterUpdatedAt:   {kind: filterTime, ops: []FilterOp{FilterLt, FilterLe, FilterGt, FilterGe}},
+	FilterDueAt:       {kind: filterTime, ops: []FilterOp{FilterEq, FilterNe, FilterLt, FilterLe, FilterGt, FilterGe}, nullable: true},
+	FilterUserID:      {kind: filterUser, ops: []FilterOp{FilterEq, FilterNe, FilterIn}},
+	FilterTeamID:      {kind: filterUser, ops: []FilterOp{FilterEq, FilterNe, FilterIn}, nullable: true},
+	FilterParentID:    {kind: filterUser, ops: []FilterOp{FilterEq, FilterNe, FilterIn}, nullable: true},
+	FilterAssignee:    {kind: filterUser, ops: []FilterOp{FilterEq, FilterNe, FilterIn}},
+	FilterWatcher:     {kind: filterUser, ops: []FilterOp{FilterEq, FilterNe, FilterIn}},
+	FilterLabel:       {kind: filterText, ops: []FilterOp{FilterEq, FilterNe, FilterIn}},
+}
+
+// a filter that couldn't be parsed. Pos is the 1-based character position of Token.
+type FilterError struct {
+	Pos   int
+	Token string
+	Msg   string
+}
+
+func (e *FilterError) Error() string {
+	if e.Token == "" {
+		return fmt.Sprintf("invalid filter: %s at position %d (end of input)", e.Msg, e.Pos)
+	}
+	return fmt.Sprintf("invalid filter: %s at position %d near %q", e.Msg, e.Pos, e.Token)
+}
+
+func (e *FilterError) Unwrap() error {
+	return ErrInvalidFilter
+}
+
+// parses and validates a filter. userID replaces "me".
+func ParseFilter(s string, userID int) (FilterExpr, error) {
+	if utf8.RuneCountInString(s) > MaxFilterLength {
+		return nil, fmt.Errorf("%w: filter is longer than %d characters", ErrInvalidFilter, MaxFilterLength)
+	}
+	tokens, err := lexFilter(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &filterParser{tokens: tokens, userID: userID}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, p.errorAt(tok, "expected and, or or the end of the filter")
+	}
+	return expr, nil
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokString // quoted
+	tokOp
+	tokLParen
+	tokRParen
+	tokComma
+)
+
+type filterToken struct {
+	kind tokenKind
+	text string
+	pos  int // 1-based, in characters
+}
+
+func lexFilter(s string) ([]filterToken, error) {
+	var tokens []filterToken
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		pos := i + 1
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, filterToken{tokLParen, "(", pos})
+			i++
+		case r == ')':
+			tokens = append(tokens, filterToken{tokRParen, ")", pos})
+			i++
+		case r == ',':
+			tokens = append(tokens, filterToken{tokComma, ",", pos})
+			i++
+		case r == '=' || r == '~':
+			tokens = append(tokens, filterToken{tokOp, string(r), pos})
+			i++
+		case r == '!' || r == '<' || r == '>':
+			op := string(r)
+			if i+1 < len(runes) && runes[i+1] == '=' {
+				op += "="
+			}
+			if op == "!" {
+				return nil, &FilterError{Pos: pos, Token: op, Msg: "unknown operator, did you mean != or not"}
+			}
+			tokens = append(tokens, filterToken{tokOp, op, pos})
+			i += len(op)
+		case r == '"':
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(runes) && runes[j] != '"'; j++ {
+				if runes[j] == '\\' && j+1 < len(runes) {
+					j++
+				}
+				b.WriteRune(runes[j])
+			}
+			if j == len(runes) {
+				return nil, &FilterError{Pos: pos, Token: string(runes[i:]), Msg: "unterminated string"}
+			}
+			tokens = append(tokens, filterToken{tokString, b.String(), pos})
+			i = j + 1
+		case isFilterWordRune(r):
+			j := i
+			for j < len(runes) && isFilterWordRune(runes[j]) {
+				j++
+			}
+			tokens = append(tokens, filterToken{tokWord, string(runes[i:j]), pos})
+			i = j
+		default:
+			return nil, &FilterError{Pos: pos, Token: string(r), Msg: "unexpected character"}
+		}
+	}
+	return append(tokens, filterToken{kind: tokEOF, pos: len(runes) + 1}), nil
+}
+
+// words cover field names, keywords, bare values, dates and timestamps, and label:x
+func isFilterWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_-.:+", r)
+}
+
+type filterParser struct {
+	tokens      []filterToken
+	next        int
+	depth       int
+	comparisons int
+	userID      int
+}
+
+func (p *filterParser) peek() filterToken {
+	return p.tokens[p.next]
+}
+
+func (p *filterParser) advance() filterToken {
+	tok := p.tokens[p.next]
+	if tok.kind != tokEOF {
+		p.next++
+	}
+	return tok
+}
+
+// reports whether the next token is the given keyword, consuming it if so
+func (p *filterParser) keyword(kw string) bool {
+	tok := p.peek()
+	if tok.kind == tokWord && strings.EqualFold(tok.text, kw) {
+		p.next++
+		return true
+	}
+	return false
+}
+
+func (p *filterParser) errorAt(tok filterToken, msg string) *FilterError {
+	return &FilterError{Pos: tok.pos, Token: tok.text, Msg: msg}
+}
+
+func (p *filterParser) parseOr() (FilterExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &FilterOr{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (FilterExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &FilterAnd{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (FilterExpr, error) {
+	tok := p.peek()
+	nests := tok.kind == tokLParen || (tok.kind == tokWord && strings.EqualFold(tok.text, "not"))
+	if nests && p.depth >= maxFilterDepth {
+		return nil, p.errorAt(tok, "filter is nested too deeply")
+	}
+	if p.keyword("not") {
+		p.depth++
+		defer func() { p.depth-- }()
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &FilterNot{Expr: expr}, nil
+	}
+	if tok.kind == tokLParen {
+		p.advance()
+		p.depth++
+		defer func() { p.depth-- }()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.advance(); closing.kind != tokRParen {
+			return nil, p.errorAt(closing, "expected )")
+		}
+		return expr, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *filterParser) parseComparison() (FilterExpr, error) {
+	tok := p.advance()
+	if tok.kind != tokWord {
+		return nil, p.errorAt(tok, "expected a field name")
+	}
+	p.comparisons++
+	if p.comparisons > MaxFilterComparisons {
+		return nil, p.errorAt(tok, fmt.Sprintf("filter has more than %d comparisons", MaxFilterComparisons))
+	}
+
+	// label:x is short for label = x
+	if name, value, ok := strings.Cut(tok.text, ":"); ok && strings.EqualFold(name, string(FilterLabel)) {
+		valueTok := filterToken{kind: tokWord, text: value, pos: tok.pos + utf8.RuneCountInString(name) + 1}
+		if value == "" {
+			return nil, p.errorAt(valueTok, "expected a label name after label:")
+		}
+		v, err := p.convert(FilterLabel, filterFields[FilterLabel], valueTok)
+		if err != nil {
+			return nil, err
+		}
+		return &FilterComparison{Field: FilterLabel, Op: FilterEq, Values: []FilterValue{v}}, nil
+	}
+
+	field := FilterField(strings.ToLower(tok.text))
+	spec, ok := filterFields[field]
+	if !ok {
+		return nil, p.errorAt(tok, "unknown field")
+	}
+
+	opTok := p.advance()
+	var op FilterOp
+	switch {
+	case opTok.kind == tokOp:
+		op = FilterOp(opTok.text)
+	case opTok.kind == tokWord && strings.EqualFold(opTok.text, "in"):
+		op = FilterIn
+	default:
+		return nil, p.errorAt(opTok, "expected an operator")
+	}
+	if !spec.allows(op) {
+		return nil, p.errorAt(opTok, fmt.Sprintf("operator %s can't be used with %s", op, field))
+	}
+
+	cmp := &FilterComparison{Field: field, Op: op}
+	if op != FilterIn {
+		v, err := p.convert(field, spec, p.advance())
+		if err != nil {
+			return nil, err
+		}
+		cmp.Values = []FilterValue{v}
+		return cmp, nil
+	}
+
+	if open := p.advance(); open.kind != tokLParen {
+		return nil, p.errorAt(open, "expected ( after in")
+	}
+	for {
+		v, err := p.convert(field, spec, p.advance())
+		if err != nil {
+			return nil, err
+		}
+		if v.Null {
+			return nil, p.errorAt(p.tokens[p.next-1], "null can't be used in a list")
+		}
+		cmp.Values = append(cmp.Values, v)
+		sep := p.advance()
+		if sep.kind == tokRParen {
+			return cmp, nil
+		}
+		if sep.kind != tokComma {
+			return nil, p.errorAt(sep, "expected , or )")
+		}
+	}
+}
+
+func (spec filterFieldSpec) allows(op FilterOp) bool {
+	for _, allowed := range spec.ops {
+		if allowed == op {
+			return true
+		}
+	}
+	return false
+}
+
+// checks a value token against the field's type
+func (p *filterParser) convert(field FilterField, spec filterFieldSpec, tok filterToken) (FilterValue, error) {
+	if tok.kind != tokWord && tok.kind != tokString {
+		return FilterValue{}, p.errorAt(tok, "expected a value")
+	}
+	if tok.kind == tokWord && strings.EqualFold(tok.text, "null") {
+		if !spec.nullable {
+			return FilterValue{}, p.errorAt(tok, fmt.Sprintf("%s is never null", field))
+		}
+		return FilterValue{Null: true}, nil
+	}
+
+	switch spec.kind {
+	case filterStatusKind:
+		if !Status(tok.text).IsValid() {
+			return FilterValue{}, p.errorAt(tok, "unknown status")
+		}
+	case filterPriorityKind:
+		if !Priority(tok.text).IsValid() {
+			return FilterValue{}, p.errorAt(tok, "unknown priority")
+		}
+	case filterTime:
+		t, err := parseFilterTime(tok.text)
+		if err != nil {
+			return FilterValue{}, p.errorAt(tok, "expected a date (YYYY-MM-DD) or RFC 3339 timestamp")
+		}
+		return FilterValue{Time: t}, nil
+	case filterUser:
+		if field == FilterAssignee || field == FilterWatcher || field == FilterUserID {
+			if strings.EqualFold(tok.text, "me") {
+				return FilterValue{Int: p.userID}, nil
+			}
+		}
+		id, err := strconv.Atoi(tok.text)
+		if err != nil || id <= 0 {
+			return FilterValue{}, p.errorAt(tok, "expected an id")
+		}
+		return FilterValue{Int: id}, nil
+	}
+	if field == FilterLabel {
+		return FilterValue{Text: strings.ToLower(tok.text)}, nil
+	}
+	return FilterValue{Text: tok.text}, nil
+}
+
+func parseFilterTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", s)
+}
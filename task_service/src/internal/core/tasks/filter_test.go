@@ -0,0 +1,161 @@
+package tasks
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// renders an expression in prefix form, e.g. (or (and a b) c), so tests can
+// compare whole trees
+func formatFilter(expr FilterExpr) string {
+	switch e := expr.(type) {
+	case *FilterAnd:
+		return "(and " + formatFilter(e.Left) + " " + formatFilter(e.Right) + ")"
+	case *FilterOr:
+		return "(or " + formatFilter(e.Left) + " " + formatFilter(e.Right) + ")"
+	case *FilterNot:
+		return "(not " + formatFilter(e.Expr) + ")"
+	case *FilterComparison:
+		values := make([]string, len(e.Values))
+		for i, v := range e.Values {
+			switch {
+			case v.Null:
+				values[i] = "null"
+			case !v.Time.IsZero():
+				values[i] = v.Time.Format("2006-01-02T15:04:05Z07:00")
+			case v.Text != "":
+				values[i] = strconv.Quote(v.Text)
+			default:
+				values[i] = strconv.Itoa(v.Int)
+			}
+		}
+		return fmt.Sprintf("%s %s %s", e.Field, e.Op, strings.Join(values, ","))
+	}
+	return fmt.Sprintf("%T", expr)
+}
+
+func TestParseFilter(t *testing.T) {
+	const me = 7
+	tests := []struct {
+		name   string
+		filter string
+		want   string
+	}{
+		{"comparison", `status = pending`, `status = "pending"`},
+		{"keywords ignore case", `status = done AND NOT priority = low`, `(and status = "done" (not priority = "low"))`},
+		{"field names ignore case", `Priority != high`, `priority != "high"`},
+		{"and binds tighter than or", `status = done or priority = high and title ~ x`,
+			`(or status = "done" (and priority = "high" title ~ "x"))`},
+		{"and before or", `status = done and priority = high or title ~ x`,
+			`(or (and status = "done" priority = "high") title ~ "x")`},
+		{"and is left-associative", `user_id = 1 and user_id = 2 and user_id = 3`,
+			`(and (and user_id = 1 user_id = 2) user_id = 3)`},
+		{"parentheses override precedence", `(status = done or priority = high) and title ~ x`,
+			`(and (or status = "done" priority = "high") title ~ "x")`},
+		{"not binds tighter than and", `not status = done and priority = low`,
+			`(and (not status = "done") priority = "low")`},
+		{"not of a group", `not (status = done or status = cancelled)`,
+			`(not (or status = "done" status = "cancelled"))`},
+		{"double not", `not not status = done`, `(not (not status = "done"))`},
+		{"in list", `status in (pending, blocked)`, `status in "pending","blocked"`},
+		{"in list of one", `priority IN (urgent)`, `priority in "urgent"`},
+		{"in list of ids", `assignee in (me, 3)`, `assignee in 7,3`},
+		{"label shorthand", `label:Bug`, `label = "bug"`},
+		{"label shorthand with not", `not label:wontfix`, `(not label = "wontfix")`},
+		{"label shorthand ignores case", `LABEL:x and label = Y`, `(and label = "x" label = "y")`},
+		{"me", `assignee = me or watcher = ME`, `(or assignee = 7 watcher = 7)`},
+		{"quoted string", `title ~ "release \"notes\""`, `title ~ "release \"notes\""`},
+		{"date", `created_at > 2026-01-01`, `created_at > 2026-01-01T00:00:00Z`},
+		{"timestamp", `updated_at <= 2026-01-01T10:00:00+02:00`, `updated_at <= 2026-01-01T10:00:00+02:00`},
+		{"null", `due_at = null`, `due_at = null`},
+		{"not null", `team_id != NULL`, `team_id != null`},
+		{"nullable field with a value", `parent_id = 4`, `parent_id = 4`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := ParseFilter(tt.filter, me)
+			if err != nil {
+				t.Fatalf("ParseFilter(%q): %v", tt.filter, err)
+			}
+			if got := formatFilter(expr); got != tt.want {
+				t.Errorf("ParseFilter(%q) = %s, want %s", tt.filter, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+		pos    int
+		token  string
+	}{
+		{"empty", ``, 1, ""},
+		{"unknown field", `colour = red`, 1, "colour"},
+		{"unknown status", `status = later`, 10, "later"},
+		{"operator not allowed", `title < x`, 7, "<"},
+		{"missing operator", `status pending`, 8, "pending"},
+		{"missing value", `status =`, 9, ""},
+		{"bang alone", `not ! status = done`, 5, "!"},
+		{"unexpected character", `status = done & priority = low`, 15, "&"},
+		{"unterminated string", `title ~ "abc`, 9, `"abc`},
+		{"trailing tokens", `status = done priority = low`, 15, "priority"},
+		{"unclosed parenthesis", `(status = done`, 15, ""},
+		{"stray closing parenthesis", `status = done)`, 14, ")"},
+		{"null in a list", `team_id in (1, null)`, 16, "null"},
+		{"null on a non-nullable field", `status = null`, 10, "null"},
+		{"null with an ordering operator", `created_at > null`, 14, "null"},
+		{"in without a list", `status in pending`, 11, "pending"},
+		{"unterminated list", `status in (pending blocked)`, 20, "blocked"},
+		{"empty label shorthand", `label: = x`, 7, ""},
+		{"bad date", `due_at < tomorrow`, 10, "tomorrow"},
+		{"bad id", `user_id = -1`, 11, "-1"},
+		{"me only for users", `team_id = me`, 11, "me"},
+		{"positions count characters", `title ~ "é" and é = 1`, 17, "é"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFilter(tt.filter, 1)
+			var ferr *FilterError
+			if !errors.As(err, &ferr) {
+				t.Fatalf("ParseFilter(%q) error = %v, want a *FilterError", tt.filter, err)
+			}
+			if !errors.Is(err, ErrInvalidFilter) {
+				t.Errorf("ParseFilter(%q) error doesn't wrap ErrInvalidFilter", tt.filter)
+			}
+			if ferr.Pos != tt.pos || ferr.Token != tt.token {
+				t.Errorf("ParseFilter(%q) error at %d near %q, want %d near %q (%v)",
+					tt.filter, ferr.Pos, ferr.Token, tt.pos, tt.token, err)
+			}
+		})
+	}
+}
+
+func TestParseFilterLimits(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+	}{
+		{"too long", `title ~ "` + strings.Repeat("a", MaxFilterLength) + `"`},
+		{"too many comparisons", strings.Repeat("user_id = 1 and ", MaxFilterComparisons) + "user_id = 1"},
+		{"nested too deeply", strings.Repeat("(", maxFilterDepth+1) + "user_id = 1" + strings.Repeat(")", maxFilterDepth+1)},
+		{"too many nots", strings.Repeat("not ", maxFilterDepth+1) + "user_id = 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFilter(tt.filter, 1)
+			if !errors.Is(err, ErrInvalidFilter) {
+				t.Errorf("ParseFilter error = %v, want ErrInvalidFilter", err)
+			}
+		})
+	}
+
+	atLimit := strings.Repeat("(", maxFilterDepth) + "user_id = 1" + strings.Repeat(")", maxFilterDepth)
+	if _, err := ParseFilter(atLimit, 1); err != nil {
+		t.Errorf("ParseFilter at the depth limit: %v", err)
+	}
+}
@@ -0,0 +1,14 @@
+package tasks
+
+// Tasks form a tree through their parent: a task can have any number of
+// subtasks, but the parent chain may never loop back to the task itself.
+
+// percentage of a task's subtasks that are done, rounded down. cancelled
+// subtasks don't count. nil when there is nothing to count.
+func Progress(subtasks, done int) *int {
+	if subtasks == 0 {
+		return nil
+	}
+	p := done * 100 / subtasks
+	return &p
+}
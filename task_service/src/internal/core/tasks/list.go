@@ -0,0 +1,150 @@
+package tasks
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// column a task list can be ordered by
+type SortField string
+
+const (
+	SortCreatedAt SortField = "created_at"
+	SortUpdatedAt SortField = "updated_at"
+	SortTitle     SortField = "title"
+	SortStatus    SortField = "status"
+	// best search matches first; only valid together with a search
+	SortRelevance SortField = "relevance"
+)
+
+// ordering of a task list. ties are always broken by id in the same direction.
+type Sort struct {
+	Field SortField
+	Desc  bool
+}
+
+// newest first
+var DefaultSort = Sort{Field: SortCreatedAt, Desc: true}
+
+// best match first
+var DefaultSearchSort = Sort{Field: SortRelevance, Desc: true}
+
+// parses "<field>" or "<field>:asc|desc", e.g. "title:asc". empty input gives DefaultSort.
+func ParseSort(s string) (Sort, error) {
+	if s == "" {
+		return DefaultSort, nil
+	}
+	field, dir, _ := strings.Cut(s, ":")
+	sort := Sort{Field: SortField(field)}
+	switch sort.Field {
+	case SortCreatedAt, SortUpdatedAt, SortTitle, SortStatus, SortRelevance:
+	default:
+		return Sort{}, fmt.Errorf("%w: unknown field %q", ErrInvalidSort, field)
+	}
+	switch dir {
+	case "", "asc":
+	case "desc":
+		sort.Desc = true
+	default:
+		return Sort{}, fmt.Errorf("%w: unknown direction %q", ErrInvalidSort, dir)
+	}
+	return sort, nil
+}
+
+func (s Sort) String() string {
+	if s.Desc {
+		return string(s.Field) + ":desc"
+	}
+	return string(s.Field) + ":asc"
+}
+
+// position after the last task of a page. clients only ever see it encoded.
+type Cursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    int    `json:"id"`
+}
+
+// builds the opaque cursor pointing just after task for the given sort.
+func EncodeCursor(sort Sort, task Task) string {
+	c := Cursor{Sort: sort.String(), ID: task.ID}
+	switch sort.Field {
+	case SortCreatedAt:
+		c.Value = task.CreatedAt.Format(time.RFC3339Nano)
+	case SortUpdatedAt:
+		c.Value = task.UpdatedAt.Format(time.RFC3339Nano)
+	case SortTitle:
+		c.Value = task.Title
+	case SortStatus:
+		c.Value = string(task.Status)
+	case SortRelevance:
+		if task.Rank != nil {
+			c.Value = strconv.FormatFloat(float64(*task.Rank), 'g', -1, 32)
+		}
+	}
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodes a cursor from a previous page. the cursor must have been issued for the same sort.
+func DecodeCursor(raw string, sort Sort) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if c.Sort != sort.String() {
+		return nil, fmt.Errorf("%w: cursor was issued for sort %q", ErrInvalidCursor, c.Sort)
+	}
+	if sort.Field == SortCreatedAt || sort.Field == SortUpdatedAt {
+		if _, err := time.Parse(time.RFC3339Nano, c.Value); err != nil {
+			return nil, ErrInvalidCursor
+		}
+	}
+	if sort.Field == SortRelevance {
+		if _, err := strconv.ParseFloat(c.Value, 32); err != nil {
+			return nil, ErrInvalidCursor
+		}
+	}
+	return &c, nil
+}
+
+// criteria for listing tasks. zero values mean "don't filter".
+type ListFilter struct {
+	UserID         int // creator
+	TeamID         int
+	ParentID       int // direct subtasks of this task
+	AssigneeID     int
+	WatcherID      int
+	VisibleTo      int      // tasks the user created, is assigned to or watches
+	Labels         []string // lower-cased label names
+	MatchAllLabels bool     // tasks must carry every label in Labels instead of any one
+	Search         []SearchTerm
+	Filter         FilterExpr // from ?filter=, on top of everything above
+	Status         Status
+	Priority       Priority
+	DueBefore      *time.Time
+	DueAfter       *time.Time
+	Overdue        bool // past due_at and still open
+	Sort           Sort
+	Limit          int
+	Cursor         *Cursor
+}
+
+// one page of a task list
+type TaskPage struct {
+	Tasks      []Task `json:"tasks"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
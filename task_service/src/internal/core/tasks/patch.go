@@ -0,0 +1,141 @@
+package tasks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"task_service/pkg/jsonpatch"
+	"time"
+	"unicode/utf8"
+)
+
+const MaxTitleLength = 255
+
+// a title must be 1 to MaxTitleLength characters
+func ValidateTitle(title string) error {
+	if title == "" || utf8.RuneCountInString(title) > MaxTitleLength {
+		return fmt.Errorf("%w: title must be 1 to %d characters", ErrInvalidTask, MaxTitleLength)
+	}
+	return nil
+}
+
+// media types PATCH /tasks/{id} accepts
+type PatchFormat string
+
+const (
+	MergePatch PatchFormat = "application/merge-patch+json" // RFC 7386
+	JSONPatch  PatchFormat = "application/json-patch+json"  // RFC 6902
+)
+
+// fields a patch may change. the rest of the task has endpoints of its own.
+var patchableFields = map[string]bool{
+	"title":       true,
+	"description": true,
+	"status":      true,
+	"priority":    true,
+	"due_at":      true,
+}
+
+// a change to a task, written against the task's JSON as returned by GET /tasks/{id}.
+// removing a field, or setting it to null, clears it.
+type Patch struct {
+	Format PatchFormat
+	Body   []byte
+	// the version the patch was written against, from If-Match; 0 skips the check
+	Version int
+}
+
+// returns a copy of task with the patch applied. only the patchable fields
+// may change, and the result must be a valid task; whether the new status can
+// be reached from the old one is left to the caller.
+func (p Patch) Apply(task *Task) (*Task, error) {
+	doc, err := json.Marshal(task)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode task: %w", err)
+	}
+
+	var patched []byte
+	switch p.Format {
+	case MergePatch:
+		patched, err = jsonpatch.MergePatch(doc, p.Body)
+	case JSONPatch:
+		patched, err = jsonpatch.Apply(doc, p.Body)
+	default:
+		return nil, fmt.Errorf("%w: unsupported format %q", ErrInvalidPatch, p.Format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkReadOnlyFields(doc, patched); err != nil {
+		return nil, err
+	}
+
+	var fields struct {
+		Title       *string    `json:"title"`
+		Description *string    `json:"description"`
+		Status      *string    `json:"status"`
+		Priority    *string    `json:"priority"`
+		DueAt       *time.Time `json:"due_at"`
+	}
+	if err := json.Unmarshal(patched, &fields); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTask, err)
+	}
+
+	result := *task
+	result.Title, result.Description, result.DueAt = "", "", fields.DueAt
+	if fields.Title != nil {
+		result.Title = *fields.Title
+	}
+	if err := ValidateTitle(result.Title); err != nil {
+		return nil, err
+	}
+	if fields.Description != nil {
+		result.Description = *fields.Description
+	}
+	if fields.Status == nil {
+		return nil, fmt.Errorf("%w: status can't be removed", ErrInvalidStatus)
+	}
+	if result.Status, err = ParseStatus(*fields.Status); err != nil {
+		return nil, err
+	}
+	if fields.Priority == nil {
+		return nil, fmt.Errorf("%w: priority can't be removed", ErrInvalidPriority)
+	}
+	if result.Priority, err = ParsePriority(*fields.Priority); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// the patched document must carry every other field unchanged, and nothing new
+func checkReadOnlyFields(before, after []byte) error {
+	var b, a map[string]interface{}
+	if err := decodeJSON(before, &b); err != nil {
+		return fmt.Errorf("could not decode task: %w", err)
+	}
+	if err := decodeJSON(after, &a); err != nil {
+		return fmt.Errorf("%w: the patched task is not a JSON object", ErrInvalidTask)
+	}
+	for field, value := range a {
+		if patchableFields[field] {
+			continue
+		}
+		if original, ok := b[field]; !ok || !reflect.DeepEqual(original, value) {
+			return fmt.Errorf("%w: %s can't be changed with a patch", ErrInvalidTask, field)
+		}
+	}
+	for field := range b {
+		if _, ok := a[field]; !ok && !patchableFields[field] {
+			return fmt.Errorf("%w: %s can't be removed", ErrInvalidTask, field)
+		}
+	}
+	return nil
+}
+
+func decodeJSON(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
@@ -0,0 +1,125 @@
+package tasks
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"task_service/internal/core/labels"
+	"testing"
+	"time"
+)
+
+func patchTestTask() *Task {
+	due := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	team := 4
+	return &Task{
+		ID:          1,
+		Title:       "write release notes",
+		Description: "for 2.0",
+		Status:      StatusInProgress,
+		Priority:    PriorityHigh,
+		DueAt:       &due,
+		UserID:      7,
+		Assignees:   []int{7, 9},
+		Watchers:    []int{},
+		TeamID:      &team,
+		BlockedBy:   []int{},
+		Blocks:      []int{},
+		Labels:      []labels.Label{{ID: 2, Name: "docs", Color: "#00ff00"}},
+		CreatedAt:   time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		UpdatedAt:   time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+		Version:     3,
+	}
+}
+
+func TestPatchApply(t *testing.T) {
+	tests := []struct {
+		name   string
+		format PatchFormat
+		body   string
+		change func(task *Task) // what the patch should do to patchTestTask
+	}{
+		{"merge: absent fields are kept", MergePatch, `{"title":"new title"}`,
+			func(task *Task) { task.Title = "new title" }},
+		{"merge: null clears the description", MergePatch, `{"description":null}`,
+			func(task *Task) { task.Description = "" }},
+		{"merge: null clears the due date", MergePatch, `{"due_at":null}`,
+			func(task *Task) { task.DueAt = nil }},
+		{"merge: several fields", MergePatch, `{"status":"in_review","priority":"low","due_at":"2026-04-01T00:00:00Z"}`,
+			func(task *Task) {
+				due := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+				task.Status, task.Priority, task.DueAt = StatusInReview, PriorityLow, &due
+			}},
+		{"merge: unchanged read-only fields are fine", MergePatch, `{"id":1,"version":3,"title":"x"}`,
+			func(task *Task) { task.Title = "x" }},
+		{"merge: empty patch", MergePatch, `{}`,
+			func(task *Task) {}},
+		{"json patch: remove clears the due date", JSONPatch, `[{"op":"remove","path":"/due_at"}]`,
+			func(task *Task) { task.DueAt = nil }},
+		{"json patch: null clears the description", JSONPatch, `[{"op":"replace","path":"/description","value":null}]`,
+			func(task *Task) { task.Description = "" }},
+		{"json patch: test then replace", JSONPatch,
+			`[{"op":"test","path":"/status","value":"in_progress"},{"op":"replace","path":"/status","value":"done"}]`,
+			func(task *Task) { task.Status = StatusDone }},
+		{"json patch: testing read-only fields is fine", JSONPatch,
+			`[{"op":"test","path":"/labels/0/name","value":"docs"},{"op":"replace","path":"/priority","value":"urgent"}]`,
+			func(task *Task) { task.Priority = PriorityUrgent }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := patchTestTask()
+			got, err := Patch{Format: tt.format, Body: []byte(tt.body)}.Apply(task)
+			if err != nil {
+				t.Fatalf("Apply: %v", err)
+			}
+			want := patchTestTask()
+			tt.change(want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Apply = %+v, want %+v", got, want)
+			}
+			if !reflect.DeepEqual(task, patchTestTask()) {
+				t.Errorf("Apply changed the task it was given")
+			}
+		})
+	}
+}
+
+func TestPatchApplyErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		format PatchFormat
+		body   string
+		err    error
+	}{
+		{"merge: null title", MergePatch, `{"title":null}`, ErrInvalidTask},
+		{"merge: empty title", MergePatch, `{"title":""}`, ErrInvalidTask},
+		{"merge: title too long", MergePatch, `{"title":"` + strings.Repeat("a", MaxTitleLength+1) + `"}`, ErrInvalidTask},
+		{"merge: null status", MergePatch, `{"status":null}`, ErrInvalidStatus},
+		{"merge: unknown status", MergePatch, `{"status":"later"}`, ErrInvalidStatus},
+		{"merge: null priority", MergePatch, `{"priority":null}`, ErrInvalidPriority},
+		{"merge: wrong type", MergePatch, `{"title":5}`, ErrInvalidTask},
+		{"merge: bad due date", MergePatch, `{"due_at":"tomorrow"}`, ErrInvalidTask},
+		{"merge: id", MergePatch, `{"id":2}`, ErrInvalidTask},
+		{"merge: version", MergePatch, `{"version":4}`, ErrInvalidTask},
+		{"merge: assignees", MergePatch, `{"assignees":[7]}`, ErrInvalidTask},
+		{"merge: removing a read-only field", MergePatch, `{"team_id":null}`, ErrInvalidTask},
+		{"merge: nested read-only field", MergePatch, `{"labels":[{"id":2,"name":"other","color":"#00ff00"}]}`, ErrInvalidTask},
+		{"merge: unknown field", MergePatch, `{"colour":"red"}`, ErrInvalidTask},
+		{"merge: not an object", MergePatch, `["title"]`, ErrInvalidTask},
+		{"merge: malformed", MergePatch, `{"title":`, ErrInvalidPatch},
+		{"json patch: remove a read-only field", JSONPatch, `[{"op":"remove","path":"/user_id"}]`, ErrInvalidTask},
+		{"json patch: add a label", JSONPatch, `[{"op":"add","path":"/labels/-","value":{"id":3,"name":"x","color":"#000000"}}]`, ErrInvalidTask},
+		{"json patch: failed test", JSONPatch, `[{"op":"test","path":"/title","value":"other"}]`, ErrPatchConflict},
+		{"json patch: missing member", JSONPatch, `[{"op":"replace","path":"/progress/x","value":1}]`, ErrPatchConflict},
+		{"json patch: malformed", JSONPatch, `{"op":"remove","path":"/due_at"}`, ErrInvalidPatch},
+		{"unsupported format", PatchFormat("application/json"), `{}`, ErrInvalidPatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Patch{Format: tt.format, Body: []byte(tt.body)}.Apply(patchTestTask())
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Apply error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
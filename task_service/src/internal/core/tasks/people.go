@@ -0,0 +1,40 @@
+package tasks
+
+import "fmt"
+
+// how a user other than the creator is attached to a task
+type Relation string
+
+const (
+	RelationAssignee Relation = "assignee" // works on the task and may edit it
+	RelationWatcher  Relation = "watcher"  // only follows updates
+)
+
+// how many users can be attached in one request
+const MaxPeoplePerRequest = 50
+
+func ParseRelation(s string) (Relation, error) {
+	switch r := Relation(s); r {
+	case RelationAssignee, RelationWatcher:
+		return r, nil
+	}
+	return "", fmt.Errorf("unknown relation %q", s)
+}
+
+func (t *Task) IsAssignee(userID int) bool {
+	return ContainsID(t.Assignees, userID)
+}
+
+func (t *Task) IsWatcher(userID int) bool {
+	return ContainsID(t.Watchers, userID)
+}
+
+// reports whether id is one of ids
+func ContainsID(ids []int, id int) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package tasks
+
+import "fmt"
+
+type Priority string
+
+const (
+	PriorityLow    Priority = "low"
+	PriorityMedium Priority = "medium"
+	PriorityHigh   Priority = "high"
+	PriorityUrgent Priority = "urgent"
+)
+
+// used when a task is created without a priority
+const DefaultPriority = PriorityMedium
+
+func ParsePriority(s string) (Priority, error) {
+	p := Priority(s)
+	if !p.IsValid() {
+		return "", fmt.Errorf("%w: %q", ErrInvalidPriority, s)
+	}
+	return p, nil
+}
+
+func (p Priority) IsValid() bool {
+	switch p {
+	case PriorityLow, PriorityMedium, PriorityHigh, PriorityUrgent:
+		return true
+	}
+	return false
+}
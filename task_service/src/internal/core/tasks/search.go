@@ -0,0 +1,63 @@
+package tasks
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	MaxSearchLength = 200 // characters in ?q=
+	MaxSearchTerms  = 20
+)
+
+// one part of a ?q= search. every term must match.
+type SearchTerm struct {
+	Text   string // a word, or the words of a phrase
+	Phrase bool   // "quoted words" must appear next to each other, in order
+	Prefix bool   // word* matches every word starting with word
+}
+
+// parses a search like `deploy "release notes" auth*`: bare words, quoted
+// phrases and prefixes ending in *. an unterminated quote runs to the end.
+func ParseSearch(q string) ([]SearchTerm, error) {
+	if utf8.RuneCountInString(q) > MaxSearchLength {
+		return nil, fmt.Errorf("%w: at most %d characters", ErrInvalidSearch, MaxSearchLength)
+	}
+	var terms []SearchTerm
+	rest := strings.TrimSpace(q)
+	for rest != "" {
+		var term SearchTerm
+		if rest[0] == '"' {
+			phrase, after, _ := strings.Cut(rest[1:], `"`)
+			term = SearchTerm{Text: strings.Join(strings.Fields(phrase), " "), Phrase: true}
+			rest = after
+		} else {
+			end := strings.IndexFunc(rest, func(r rune) bool { return unicode.IsSpace(r) || r == '"' })
+			if end < 0 {
+				end = len(rest)
+			}
+			word := rest[:end]
+			rest = rest[end:]
+			term = SearchTerm{Text: strings.TrimRight(word, "*")}
+			term.Prefix = term.Text != word
+		}
+		rest = strings.TrimSpace(rest)
+		if term.Text == "" {
+			continue
+		}
+		terms = append(terms, term)
+	}
+	if len(terms) > MaxSearchTerms {
+		return nil, fmt.Errorf("%w: at most %d terms", ErrInvalidSearch, MaxSearchTerms)
+	}
+	return terms, nil
+}
+
+// search matches in a task, with matched words wrapped in <mark></mark>.
+// the rest of the text is HTML-escaped, so it can be shown as HTML.
+type Highlight struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"` // the best fragments only
+}
@@ -0,0 +1,80 @@
+package tasks
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseSearch(t *testing.T) {
+	tests := []struct {
+		name string
+		q    string
+		want []SearchTerm
+	}{
+		{"empty", ``, nil},
+		{"only spaces", "  \t ", nil},
+		{"words", `deploy auth`, []SearchTerm{{Text: "deploy"}, {Text: "auth"}}},
+		{"extra spaces", "  deploy \t  auth  ", []SearchTerm{{Text: "deploy"}, {Text: "auth"}}},
+		{"phrase", `"release notes"`, []SearchTerm{{Text: "release notes", Phrase: true}}},
+		{"spaces in a phrase collapse", `"  release   notes "`, []SearchTerm{{Text: "release notes", Phrase: true}}},
+		{"mixed", `deploy "release notes" auth*`, []SearchTerm{
+			{Text: "deploy"}, {Text: "release notes", Phrase: true}, {Text: "auth", Prefix: true}}},
+		{"unterminated quote runs to the end", `fix "login page`, []SearchTerm{
+			{Text: "fix"}, {Text: "login page", Phrase: true}}},
+		{"empty phrase is dropped", `"" deploy "  "`, []SearchTerm{{Text: "deploy"}}},
+		{"quote ends a word", `fix"login page"`, []SearchTerm{
+			{Text: "fix"}, {Text: "login page", Phrase: true}}},
+		{"phrase without a space after it", `"login"page`, []SearchTerm{
+			{Text: "login", Phrase: true}, {Text: "page"}}},
+		{"prefix", `auth*`, []SearchTerm{{Text: "auth", Prefix: true}}},
+		{"several stars are one prefix", `auth***`, []SearchTerm{{Text: "auth", Prefix: true}}},
+		{"star alone is dropped", `* deploy **`, []SearchTerm{{Text: "deploy"}}},
+		{"star inside a word is kept", `a*b *ab`, []SearchTerm{{Text: "a*b"}, {Text: "*ab"}}},
+		{"star in a phrase is kept", `"auth*"`, []SearchTerm{{Text: "auth*", Phrase: true}}},
+		{"backslashes are kept", `C:\temp\ "a\"`, []SearchTerm{
+			{Text: `C:\temp\`}, {Text: `a\`, Phrase: true}}},
+		{"single quotes are kept", `it's o'clock*`, []SearchTerm{
+			{Text: "it's"}, {Text: "o'clock", Prefix: true}}},
+		{"non-ASCII", `überprüfung "日本 語"`, []SearchTerm{
+			{Text: "überprüfung"}, {Text: "日本 語", Phrase: true}}},
+		{"longest search", strings.Repeat("ü", MaxSearchLength), []SearchTerm{{Text: strings.Repeat("ü", MaxSearchLength)}}},
+		{"most terms", strings.Repeat("a ", MaxSearchTerms), func() []SearchTerm {
+			terms := make([]SearchTerm, MaxSearchTerms)
+			for i := range terms {
+				terms[i] = SearchTerm{Text: "a"}
+			}
+			return terms
+		}()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSearch(tt.q)
+			if err != nil {
+				t.Fatalf("ParseSearch(%q): %v", tt.q, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseSearch(%q) = %+v, want %+v", tt.q, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSearchErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		q    string
+	}{
+		{"too long", strings.Repeat("ü", MaxSearchLength+1)},
+		{"too many terms", strings.Repeat("a ", MaxSearchTerms+1)},
+		{"too many phrases", strings.Repeat(`"a b" `, MaxSearchTerms+1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseSearch(tt.q); !errors.Is(err, ErrInvalidSearch) {
+				t.Errorf("ParseSearch error = %v, want ErrInvalidSearch", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package tasks
+
+import "fmt"
+
+// lifecycle state of a task. only the values below are valid.
+type Status string
+
+const (
+	StatusPending    Status = "pending"
+	StatusInProgress Status = "in_progress"
+	StatusBlocked    Status = "blocked"
+	StatusInReview   Status = "in_review"
+	StatusDone       Status = "done"
+	StatusCancelled  Status = "cancelled"
+)
+
+// allowed moves from each status. moving to the same status is always allowed.
+var statusTransitions = map[Status][]Status{
+	StatusPending:    {StatusInProgress, StatusBlocked, StatusCancelled},
+	StatusInProgress: {StatusPending, StatusBlocked, StatusInReview, StatusDone, StatusCancelled},
+	StatusBlocked:    {StatusPending, StatusInProgress, StatusCancelled},
+	StatusInReview:   {StatusInProgress, StatusBlocked, StatusDone, StatusCancelled},
+	StatusDone:       {StatusInProgress}, // reopening
+	StatusCancelled:  {StatusPending},    // restoring
+}
+
+// converts raw input into a Status, rejecting anything not in the list above.
+func ParseStatus(s string) (Status, error) {
+	status := Status(s)
+	if !status.IsValid() {
+		return "", fmt.Errorf("%w: %q", ErrInvalidStatus, s)
+	}
+	return status, nil
+}
+
+func (s Status) IsValid() bool {
+	_, ok := statusTransitions[s]
+	return ok
+}
+
+// done and cancelled tasks are closed; everything else is still open.
+func (s Status) IsClosed() bool {
+	return s == StatusDone || s == StatusCancelled
+}
+
+// reports whether a task in status s may be moved to next.
+func (s Status) CanTransitionTo(next Status) bool {
+	if s == next {
+		return true
+	}
+	for _, allowed := range statusTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// same as CanTransitionTo but returns an error describing the rejected move.
+func (s Status) ValidateTransition(next Status) error {
+	if !next.IsValid() {
+		return fmt.Errorf("%w: %q", ErrInvalidStatus, next)
+	}
+	if !s.CanTransitionTo(next) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, s, next)
+	}
+	return nil
+}
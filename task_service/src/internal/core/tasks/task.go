@@ -1,13 +1,34 @@
 package tasks
 
-import "time"
+import (
+	"task_service/internal/core/labels"
+	"time"
+)
 
 type Task struct {
-	ID          int       `json:"id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Status      string    `json:"status"`
-	UserID      int       `json:"user_id"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          int            `json:"id"`
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Status      Status         `json:"status"`
+	Priority    Priority       `json:"priority"`
+	DueAt       *time.Time     `json:"due_at"`  // nil when the task has no due date
+	UserID      int            `json:"user_id"` // creator and owner
+	Assignees   []int          `json:"assignees"`
+	Watchers    []int          `json:"watchers"`
+	TeamID      *int           `json:"team_id"`    // team the task is shared with, nil for personal tasks
+	ParentID    *int           `json:"parent_id"`  // nil for top-level tasks
+	Progress    *int           `json:"progress"`   // percentage of subtasks done, nil without subtasks
+	BlockedBy   []int          `json:"blocked_by"` // prerequisites: tasks this one depends on
+	Blocks      []int          `json:"blocks"`     // dependents: tasks depending on this one
+	BlockedFrom Status         `json:"-"`          // set while the task is blocked by its prerequisites
+	Labels      []labels.Label `json:"labels"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	// goes up by one on every change. on updates, a non-zero Version is the
+	// version the change was based on, and the update fails if it is stale.
+	Version int `json:"version"`
+
+	// only set on search results
+	Rank      *float32   `json:"rank,omitempty"`
+	Highlight *Highlight `json:"highlight,omitempty"`
 }
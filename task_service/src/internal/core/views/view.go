@@ -0,0 +1,161 @@
+package views
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"task_service/internal/core/tasks"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	ErrViewNotFound    = errors.New("view not found")
+	ErrInvalidViewName = errors.New("invalid view name")
+	ErrInvalidColumns  = errors.New("invalid view columns")
+	ErrDuplicateView   = errors.New("a view with this name already exists")
+)
+
+const MaxNameLength = 100
+
+// task fields a view can show, named as in the task JSON
+var Columns = []string{
+	"id", "title", "description", "status", "priority", "due_at", "user_id", "assignees", "watchers",
+	"team_id", "parent_id", "progress", "blocked_by", "blocks", "labels", "created_at", "updated_at", "version",
+}
+
+// used when a view is saved without columns
+var DefaultColumns = []string{"id", "title", "status", "priority", "due_at", "assignees", "labels"}
+
+// a saved GET /tasks query. like a label, it belongs either to a single user
+// (OwnerID) or to a team (TeamID), and names are unique within that scope.
+// a team view only ever lists the team's tasks.
+type View struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Filter    string    `json:"filter"`  // in the ?filter= language of GET /tasks, may be empty
+	Sort      string    `json:"sort"`    // as in ?sort=, empty for the default order
+	Columns   []string  `json:"columns"` // in display order
+	OwnerID   *int      `json:"owner_id,omitempty"`
+	TeamID    *int      `json:"team_id,omitempty"`
+	CreatedBy int       `json:"created_by"`
+	IsDefault bool      `json:"is_default"` // whether this is the caller's default view
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// trims and checks everything the user typed in. the filter is only checked
+// here; "me" in it is resolved for whoever runs the view.
+func (v *View) Normalize() error {
+	name := strings.TrimSpace(v.Name)
+	if name == "" || utf8.RuneCountInString(name) > MaxNameLength {
+		return fmt.Errorf("%w: %q must be 1 to %d characters", ErrInvalidViewName, name, MaxNameLength)
+	}
+	v.Name = name
+
+	v.Filter = strings.TrimSpace(v.Filter)
+	if v.Filter != "" {
+		if _, err := tasks.ParseFilter(v.Filter, 0); err != nil {
+			return err
+		}
+	}
+
+	v.Sort = strings.TrimSpace(v.Sort)
+	sort, err := tasks.ParseSort(v.Sort)
+	if err != nil {
+		return err
+	}
+	if sort.Field == tasks.SortRelevance {
+		return fmt.Errorf("%w: views can't be sorted by relevance", tasks.ErrInvalidSort)
+	}
+
+	columns, err := ParseColumns(v.Columns)
+	if err != nil {
+		return err
+	}
+	v.Columns = columns
+	return nil
+}
+
+// the query a view runs. userID is the caller, who "me" stands for.
+func (v *View) ListFilter(userID int) (tasks.ListFilter, error) {
+	filter := tasks.ListFilter{Limit: tasks.DefaultPageSize}
+	if v.TeamID != nil {
+		filter.TeamID = *v.TeamID
+	}
+	if v.Filter != "" {
+		expr, err := tasks.ParseFilter(v.Filter, userID)
+		if err != nil {
+			return filter, err
+		}
+		filter.Filter = expr
+	}
+	sort, err := tasks.ParseSort(v.Sort)
+	if err != nil {
+		return filter, err
+	}
+	filter.Sort = sort
+	return filter, nil
+}
+
+// a page of a view's tasks, each holding only the view's columns and its id
+type TaskPage struct {
+	Columns    []string                     `json:"columns"` // in display order
+	Tasks      []map[string]json.RawMessage `json:"tasks"`
+	NextCursor string                       `json:"next_cursor,omitempty"`
+}
+
+// cuts the tasks down to the view's columns. the id is always kept, so rows
+// can be linked to the task.
+func (v *View) Project(page *tasks.TaskPage) (*TaskPage, error) {
+	projected := &TaskPage{
+		Columns:    v.Columns,
+		Tasks:      make([]map[string]json.RawMessage, 0, len(page.Tasks)),
+		NextCursor: page.NextCursor,
+	}
+	for i := range page.Tasks {
+		data, err := json.Marshal(&page.Tasks[i])
+		if err != nil {
+			return nil, fmt.Errorf("could not encode task: %w", err)
+		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return nil, fmt.Errorf("could not decode task: %w", err)
+		}
+		row := map[string]json.RawMessage{"id": fields["id"]}
+		for _, column := range v.Columns {
+			row[column] = fields[column]
+		}
+		projected.Tasks = append(projected.Tasks, row)
+	}
+	return projected, nil
+}
+
+// checks columns against Columns and drops repeats. no columns gives DefaultColumns.
+func ParseColumns(columns []string) ([]string, error) {
+	if len(columns) == 0 {
+		return append([]string(nil), DefaultColumns...), nil
+	}
+	seen := map[string]bool{}
+	var parsed []string
+	for _, column := range columns {
+		if !isColumn(column) {
+			return nil, fmt.Errorf("%w: unknown column %q", ErrInvalidColumns, column)
+		}
+		if !seen[column] {
+			seen[column] = true
+			parsed = append(parsed, column)
+		}
+	}
+	return parsed, nil
+}
+
+func isColumn(column string) bool {
+	for _, c := range Columns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"task_service/internal/core/comments"
+	"task_service/internal/usecase"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type CommentHandler struct {
+	commentUsecase usecase.CommentUsecase
+}
+
+func NewCommentHandler(uc usecase.CommentUsecase) *CommentHandler {
+	return &CommentHandler{
+		commentUsecase: uc,
+	}
+}
+
+// the author is taken from the bearer token
+type CommentRequest struct {
+	Body string `json:"body"`
+}
+
+// for POST /tasks/{id}/comments endpoint
+func (h *CommentHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		http.Error(w, "Could not retrieve user ID from context", http.StatusInternalServerError)
+		return
+	}
+
+	taskID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	var req CommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	comment, err := h.commentUsecase.CreateComment(r.Context(), actor, taskID, req.Body)
+	if err != nil {
+		writeCommentError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(comment)
+}
+
+// for GET /tasks/{id}/comments endpoint. oldest first, supports ?limit=&cursor=
+func (h *CommentHandler) ListComments(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		http.Error(w, "Could not retrieve user ID from context", http.StatusInternalServerError)
+		return
+	}
+
+	taskID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	filter := comments.ListFilter{TaskID: taskID, Limit: comments.DefaultPageSize}
+	q := r.URL.Query()
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > comments.MaxPageSize {
+			http.Error(w, "limit must be between 1 and "+strconv.Itoa(comments.MaxPageSize), http.StatusBadRequest)
+			return
+		}
+		filter.Limit = limit
+	}
+	if v := q.Get("cursor"); v != "" {
+		afterID, err := comments.DecodeCursor(v)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		filter.AfterID = afterID
+	}
+
+	page, err := h.commentUsecase.ListComments(r.Context(), actor, filter)
+	if err != nil {
+		writeCommentError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(page)
+}
+
+// for PUT /tasks/{id}/comments/{comment_id} endpoint
+func (h *CommentHandler) UpdateComment(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		http.Error(w, "Could not retrieve user ID from context", http.StatusInternalServerError)
+		return
+	}
+
+	taskID, commentID, ok := commentIDsFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req CommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	comment, err := h.commentUsecase.UpdateComment(r.Context(), actor, taskID, commentID, req.Body)
+	if err != nil {
+		writeCommentError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(comment)
+}
+
+// for DELETE /tasks/{id}/comments/{comment_id} endpoint
+func (h *CommentHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		http.Error(w, "Could not retrieve user ID from context", http.StatusInternalServerError)
+		return
+	}
+
+	taskID, commentID, ok := commentIDsFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.commentUsecase.DeleteComment(r.Context(), actor, taskID, commentID); err != nil {
+		writeCommentError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// parses the task and comment ids from the path, writing a 400 if either is invalid
+func commentIDsFromRequest(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	taskID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid task ID", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	commentID, err := strconv.Atoi(chi.URLParam(r, "comment_id"))
+	if err != nil {
+		http.Error(w, "Invalid comment ID", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	return taskID, commentID, true
+}
+
+// maps comment errors to http status codes; task errors are handled by writeError
+func writeCommentError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, comments.ErrCommentNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, comments.ErrEmptyComment), errors.Is(err, comments.ErrCommentTooLong):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	default:
+		writeError(w, err)
+	}
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"task_service/internal/core/tasks"
+)
+
+// a task's entity tag is its quoted version and a hash of its JSON, e.g.
+// "7-3f2a9c0d1e4b5a6f". the version only changes with the fields a client
+// writes, while the hash also follows progress, dependencies, labels and
+// everything else GET returns.
+func taskETag(task *tasks.Task) string {
+	data, _ := json.Marshal(task) // plain data, encoding can't fail
+	sum := sha256.Sum256(data)
+	return fmt.Sprintf(`"%d-%s"`, task.Version, hex.EncodeToString(sum[:8]))
+}
+
+// the version an If-Match header asks for: 0 for a missing header or *, which
+// any existing task matches. only a single tag is supported. only the version in
+// front of the "-" is compared and the hash after it is ignored, so "7-garbage"
+// matches version 7: what the hash adds can't be changed through PUT or PATCH,
+// so a write can't lose it. weak tags and tags without a version give -1, which
+// no task matches.
+func parseIfMatch(r *http.Request) (int, error) {
+	v := strings.TrimSpace(r.Header.Get("If-Match"))
+	if v == "" || v == "*" {
+		return 0, nil
+	}
+	if strings.Contains(v, ",") {
+		return 0, errors.New("If-Match with several entity tags is not supported")
+	}
+	if strings.HasPrefix(v, "W/") {
+		return -1, nil
+	}
+	unquoted, err := strconv.Unquote(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid If-Match %s", v)
+	}
+	versionPart, _, ok := strings.Cut(unquoted, "-")
+	if !ok {
+		return -1, nil
+	}
+	version, err := strconv.Atoi(versionPart)
+	if err != nil || version < 1 {
+		return -1, nil
+	}
+	return version, nil
+}
+
+// reports whether an If-None-Match header matches etag, using the weak
+// comparison it calls for
+func ifNoneMatch(r *http.Request, etag string) bool {
+	v := strings.TrimSpace(r.Header.Get("If-None-Match"))
+	if v == "" {
+		return false
+	}
+	if v == "*" {
+		return true
+	}
+	for _, tag := range strings.Split(v, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(tag), "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"task_service/internal/core/tasks"
+	"testing"
+)
+
+func TestParseIfMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string // not sent when empty
+		want    int
+		wantErr bool
+	}{
+		{"missing", ``, 0, false},
+		{"only spaces", `   `, 0, false},
+		{"any", `*`, 0, false},
+		{"issued tag", `"7-3f2a9c0d1e4b5a6f"`, 7, false},
+		{"spaces around the tag", `  "7-3f2a9c0d1e4b5a6f" `, 7, false},
+		{"the hash is not compared", `"7-garbage"`, 7, false},
+		{"empty hash", `"7-"`, 7, false},
+		{"weak tag", `W/"7-3f2a9c0d1e4b5a6f"`, -1, false},
+		{"no hash", `"7"`, -1, false},
+		{"version not a number", `"x-3f2a9c0d1e4b5a6f"`, -1, false},
+		{"zero version", `"0-3f2a9c0d1e4b5a6f"`, -1, false},
+		{"negative version", `"-1-3f2a9c0d1e4b5a6f"`, -1, false},
+		{"unquoted", `7-3f2a9c0d1e4b5a6f`, 0, true},
+		{"several tags", `"7-a", "8-b"`, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("PUT", "/tasks/1", nil)
+			if tt.header != "" {
+				r.Header.Set("If-Match", tt.header)
+			}
+			got, err := parseIfMatch(r)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseIfMatch(%s) error = %v, want error %v", tt.header, err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("parseIfMatch(%s) = %d, want %d", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIfNoneMatch(t *testing.T) {
+	task := &tasks.Task{ID: 1, Title: "write release notes", Version: 7}
+	etag := taskETag(task)
+	other := taskETag(&tasks.Task{ID: 1, Title: "write release notes", Version: 8})
+
+	tests := []struct {
+		name   string
+		header string // not sent when empty
+		want   bool
+	}{
+		{"missing", ``, false},
+		{"any", `*`, true},
+		{"same tag", etag, true},
+		{"weak tag matches too", `W/` + etag, true},
+		{"spaces around the tag", "  " + etag + " ", true},
+		{"one of several", other + `, ` + etag, true},
+		{"one of several, weak", other + `,W/` + etag, true},
+		{"other version", other, false},
+		{"same version, other hash", `"7-0000000000000000"`, false},
+		{"unquoted", etag[1 : len(etag)-1], false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/tasks/1", nil)
+			if tt.header != "" {
+				r.Header.Set("If-None-Match", tt.header)
+			}
+			if got := ifNoneMatch(r, etag); got != tt.want {
+				t.Errorf("ifNoneMatch(%s) = %v, want %v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"task_service/internal/core/labels"
+	"task_service/internal/usecase"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type LabelHandler struct {
+	labelUsecase usecase.LabelUsecase
+}
+
+func NewLabelHandler(uc usecase.LabelUsecase) *LabelHandler {
+	return &LabelHandler{
+		labelUsecase: uc,
+	}
+}
+
+type LabelRequest struct {
+	Name   string `json:"name"`
+	Color  string `json:"color"`   // #rrggbb, defaults to grey
+	TeamID *int   `json:"team_id"` // only on create; makes it a team label
+}
+
+// for POST /labels endpoint
+func (h *LabelHandler) CreateLabel(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		http.Error(w, "Could not retrieve user ID from context", http.StatusInternalServerError)
+		return
+	}
+
+	var req LabelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	label := &labels.Label{Name: req.Name, Color: req.Color, TeamID: req.TeamID}
+	if err := h.labelUsecase.CreateLabel(r.Context(), actor, label); err != nil {
+		writeLabelError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(label)
+}
+
+// for GET /labels endpoint. ?team_id= lists a team's labels instead of the caller's.
+func (h *LabelHandler) ListLabels(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		http.Error(w, "Could not retrieve user ID from context", http.StatusInternalServerError)
+		return
+	}
+
+	var teamID int
+	if v := r.URL.Query().Get("team_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "invalid team_id", http.StatusBadRequest)
+			return
+		}
+		teamID = id
+	}
+
+	labelList, err := h.labelUsecase.ListLabels(r.Context(), actor, teamID)
+	if err != nil {
+		writeLabelError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(labelList)
+}
+
+// for PUT /labels/{id} endpoint
+func (h *LabelHandler) UpdateLabel(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		http.Error(w, "Could not retrieve user ID from context", http.StatusInternalServerError)
+		return
+	}
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid label ID", http.StatusBadRequest)
+		return
+	}
+
+	var req LabelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	label, err := h.labelUsecase.UpdateLabel(r.Context(), actor, &labels.Label{ID: id, Name: req.Name, Color: req.Color})
+	if err != nil {
+		writeLabelError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(label)
+}
+
+// for DELETE /labels/{id} endpoint
+func (h *LabelHandler) DeleteLabel(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		http.Error(w, "Could not retrieve user ID from context", http.StatusInternalServerError)
+		return
+	}
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid label ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.labelUsecase.DeleteLabel(r.Context(), actor, id); err != nil {
+		writeLabelError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type AttachLabelRequest struct {
+	LabelID int `json:"label_id"`
+}
+
+// for POST /tasks/{id}/labels endpoint
+func (h *LabelHandler) AttachLabel(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		http.Error(w, "Could not retrieve user ID from context", http.StatusInternalServerError)
+		return
+	}
+
+	taskID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	var req AttachLabelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.LabelID == 0 {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	task, err := h.labelUsecase.AttachLabel(r.Context(), actor, taskID, req.LabelID)
+	if err != nil {
+		writeLabelError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(task)
+}
+
+// for DELETE /tasks/{id}/labels/{label_id} endpoint
+func (h *LabelHandler) DetachLabel(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		http.Error(w, "Could not retrieve user ID from context", http.StatusInternalServerError)
+		return
+	}
+
+	taskID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+	labelID, err := strconv.Atoi(chi.URLParam(r, "label_id"))
+	if err != nil {
+		http.Error(w, "Invalid label ID", http.StatusBadRequest)
+		return
+	}
+
+	task, err := h.labelUsecase.DetachLabel(r.Context(), actor, taskID, labelID)
+	if err != nil {
+		writeLabelError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(task)
+}
+
+// maps label errors to http status codes; task errors are handled by writeError
+func writeLabelError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, labels.ErrLabelNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, labels.ErrInvalidLabelName), errors.Is(err, labels.ErrInvalidColor), errors.Is(err, labels.ErrLabelNotUsable):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	case errors.Is(err, labels.ErrDuplicateLabel):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		writeError(w, err)
+	}
+}
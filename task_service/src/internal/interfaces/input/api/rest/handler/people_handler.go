@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"task_service/internal/core/tasks"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type AddPeopleRequest struct {
+	UserIDs []int `json:"user_ids"`
+}
+
+// for POST /tasks/{id}/assignees endpoint
+func (h *TaskHandler) AddAssignees(w http.ResponseWriter, r *http.Request) {
+	h.addPeople(w, r, tasks.RelationAssignee)
+}
+
+// for POST /tasks/{id}/watchers endpoint
+func (h *TaskHandler) AddWatchers(w http.ResponseWriter, r *http.Request) {
+	h.addPeople(w, r, tasks.RelationWatcher)
+}
+
+// for DELETE /tasks/{id}/assignees/{user_id} endpoint
+func (h *TaskHandler) RemoveAssignee(w http.ResponseWriter, r *http.Request) {
+	h.removePerson(w, r, tasks.RelationAssignee)
+}
+
+// for DELETE /tasks/{id}/watchers/{user_id} endpoint. user_id may be "me".
+func (h *TaskHandler) RemoveWatcher(w http.ResponseWriter, r *http.Request) {
+	h.removePerson(w, r, tasks.RelationWatcher)
+}
+
+func (h *TaskHandler) addPeople(w http.ResponseWriter, r *http.Request, relation tasks.Relation) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		http.Error(w, "Could not retrieve user ID from context", http.StatusInternalServerError)
+		return
+	}
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	var req AddPeopleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	task, err := h.taskUsecase.AddPeople(r.Context(), actor, id, relation, req.UserIDs)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(task)
+}
+
+func (h *TaskHandler) removePerson(w http.ResponseWriter, r *http.Request, relation tasks.Relation) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		http.Error(w, "Could not retrieve user ID from context", http.StatusInternalServerError)
+		return
+	}
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+	userID, err := parseUserParam(chi.URLParam(r, "user_id"), actor)
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	task, err := h.taskUsecase.RemovePerson(r.Context(), actor, id, relation, userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(task)
+}
@@ -2,10 +2,17 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
 	"net/http"
 	"strconv"
+	"strings"
 	"task_service/internal/core/tasks"
+	"task_service/internal/interfaces/input/api/rest/middleware"
 	"task_service/internal/usecase"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 )
@@ -20,14 +27,26 @@ func NewTaskHandler(uc usecase.TaskUsecase) *TaskHandler {
 	}
 }
 
+// the owner is taken from the bearer token, not from the body
 type CreateTaskRequest struct {
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	UserID      int    `json:"user_id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Priority    string     `json:"priority"` // defaults to medium
+	DueAt       *time.Time `json:"due_at"`
+	TeamID      *int       `json:"team_id"`   // shares the task with a team the caller belongs to
+	Assignees   []int      `json:"assignees"` // defaults to the caller
+	Watchers    []int      `json:"watchers"`
+	ParentID    *int       `json:"parent_id"` // makes the task a subtask
 }
 
 // for POST /tasks endpoint
 func (h *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		http.Error(w, "Could not retrieve user ID from context", http.StatusInternalServerError)
+		return
+	}
+
 	var req CreateTaskRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
@@ -36,14 +55,23 @@ func (h *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
 	task := &tasks.Task{
 		Title:       req.Title,
 		Description: req.Description,
-		UserID:      req.UserID,
+		Priority:    tasks.DefaultPriority,
+		DueAt:       req.DueAt,
+		TeamID:      req.TeamID,
+		Assignees:   req.Assignees,
+		Watchers:    req.Watchers,
+		ParentID:    req.ParentID,
 	}
-	if err := h.taskUsecase.CreateTask(r.Context(), task); err != nil {
-		if err.Error()[:17] == "invalid user ID:" {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+	if req.Priority != "" {
+		priority, err := tasks.ParsePriority(req.Priority)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
 			return
 		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		task.Priority = priority
+	}
+	if err := h.taskUsecase.CreateTask(r.Context(), actor, task); err != nil {
+		writeError(w, err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -51,28 +79,244 @@ func (h *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(task)
 }
 
-// for GET /tasks endpoint
+// for GET /tasks endpoint.
+// supports ?q=&filter=&user_id=&team_id=&parent_id=&assignee=&watcher=&label=&label_match=any|all&status=&priority=&due_before=&due_after=&overdue=true
+// &sort=<field>[:asc|desc]&limit=&cursor=
+// q is a full-text search over title and description; results are ranked by relevance
+// unless sort is given, and carry highlighted snippets.
+// filter takes an expression like `status in (pending, blocked) and not label:wontfix`
+// (see core/tasks/filter.go); it narrows the other parameters rather than replacing them.
 func (h *TaskHandler) ListTasks(w http.ResponseWriter, r *http.Request) {
-	userID := r.URL.Query().Get("user_id")
-	status := r.URL.Query().Get("status")
-	tasks, err := h.taskUsecase.ListTasks(r.Context(), userID, status)
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		http.Error(w, "Could not retrieve user ID from context", http.StatusInternalServerError)
+		return
+	}
+
+	filter, err := parseListFilter(r, actor)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	page, err := h.taskUsecase.ListTasks(r.Context(), actor, filter)
+	if err != nil {
+		writeError(w, err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(tasks)
+	json.NewEncoder(w).Encode(page)
 }
 
+func parseListFilter(r *http.Request, actor tasks.Actor) (tasks.ListFilter, error) {
+	q := r.URL.Query()
+	var filter tasks.ListFilter
+
+	if v := q.Get("user_id"); v != "" {
+		userID, err := strconv.Atoi(v)
+		if err != nil {
+			return filter, fmt.Errorf("invalid user_id %q", v)
+		}
+		filter.UserID = userID
+	}
+	if v := q.Get("team_id"); v != "" {
+		teamID, err := strconv.Atoi(v)
+		if err != nil {
+			return filter, fmt.Errorf("invalid team_id %q", v)
+		}
+		filter.TeamID = teamID
+	}
+	if v := q.Get("parent_id"); v != "" {
+		parentID, err := strconv.Atoi(v)
+		if err != nil {
+			return filter, fmt.Errorf("invalid parent_id %q", v)
+		}
+		filter.ParentID = parentID
+	}
+	if v := q.Get("assignee"); v != "" {
+		assigneeID, err := parseUserParam(v, actor)
+		if err != nil {
+			return filter, fmt.Errorf("invalid assignee %q", v)
+		}
+		filter.AssigneeID = assigneeID
+	}
+	if v := q.Get("watcher"); v != "" {
+		watcherID, err := parseUserParam(v, actor)
+		if err != nil {
+			return filter, fmt.Errorf("invalid watcher %q", v)
+		}
+		filter.WatcherID = watcherID
+	}
+	// repeated ?label= params; tasks match if they carry any of them unless label_match=all
+	seen := map[string]bool{}
+	for _, v := range q["label"] {
+		name := strings.ToLower(strings.TrimSpace(v))
+		if name != "" && !seen[name] {
+			seen[name] = true
+			filter.Labels = append(filter.Labels, name)
+		}
+	}
+	switch v := q.Get("label_match"); v {
+	case "", "any":
+	case "all":
+		filter.MatchAllLabels = true
+	default:
+		return filter, fmt.Errorf("invalid label_match %q, expected any or all", v)
+	}
+	if v := q.Get("status"); v != "" {
+		status, err := tasks.ParseStatus(v)
+		if err != nil {
+			return filter, err
+		}
+		filter.Status = status
+	}
+	if v := q.Get("priority"); v != "" {
+		priority, err := tasks.ParsePriority(v)
+		if err != nil {
+			return filter, err
+		}
+		filter.Priority = priority
+	}
+	if v := q.Get("due_before"); v != "" {
+		t, err := parseTimeParam(v)
+		if err != nil {
+			return filter, fmt.Errorf("invalid due_before: %w", err)
+		}
+		filter.DueBefore = &t
+	}
+	if v := q.Get("due_after"); v != "" {
+		t, err := parseTimeParam(v)
+		if err != nil {
+			return filter, fmt.Errorf("invalid due_after: %w", err)
+		}
+		filter.DueAfter = &t
+	}
+	if v := q.Get("overdue"); v != "" {
+		overdue, err := strconv.ParseBool(v)
+		if err != nil {
+			return filter, fmt.Errorf("invalid overdue %q", v)
+		}
+		filter.Overdue = overdue
+	}
+
+	if v := q.Get("q"); v != "" {
+		terms, err := tasks.ParseSearch(v)
+		if err != nil {
+			return filter, err
+		}
+		filter.Search = terms
+	}
+	if v := q.Get("filter"); v != "" {
+		expr, err := tasks.ParseFilter(v, actor.UserID)
+		if err != nil {
+			return filter, err
+		}
+		filter.Filter = expr
+	}
+
+	sort, err := tasks.ParseSort(q.Get("sort"))
+	if err != nil {
+		return filter, err
+	}
+	if q.Get("sort") == "" && len(filter.Search) > 0 {
+		sort = tasks.DefaultSearchSort
+	}
+	if sort.Field == tasks.SortRelevance && len(filter.Search) == 0 {
+		return filter, fmt.Errorf("%w: relevance needs a search in q", tasks.ErrInvalidSort)
+	}
+	filter.Sort = sort
+
+	filter.Limit = tasks.DefaultPageSize
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > tasks.MaxPageSize {
+			return filter, fmt.Errorf("limit must be between 1 and %d", tasks.MaxPageSize)
+		}
+		filter.Limit = limit
+	}
+	if v := q.Get("cursor"); v != "" {
+		cursor, err := tasks.DecodeCursor(v, sort)
+		if err != nil {
+			return filter, err
+		}
+		filter.Cursor = cursor
+	}
+	return filter, nil
+}
+
+// accepts a user id or "me" for the caller
+func parseUserParam(v string, actor tasks.Actor) (int, error) {
+	if v == "me" {
+		return actor.UserID, nil
+	}
+	return strconv.Atoi(v)
+}
+
+// accepts a full RFC 3339 timestamp or a plain date (midnight UTC)
+func parseTimeParam(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected RFC 3339 timestamp or YYYY-MM-DD, got %q", v)
+	}
+	return t, nil
+}
+
+// for GET /tasks/{id} endpoint. sends the task's ETag and answers a matching
+// If-None-Match with 304 Not Modified.
+func (h *TaskHandler) GetTask(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		http.Error(w, "Could not retrieve user ID from context", http.StatusInternalServerError)
+		return
+	}
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	task, err := h.taskUsecase.GetTask(r.Context(), actor, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	etag := taskETag(task)
+	w.Header().Set("ETag", etag)
+	if ifNoneMatch(r, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(task)
+}
+
+// the whole editable state of a task. title, status and priority are required;
+// a missing description or due_at clears it.
 type UpdateTaskRequest struct {
-	Title       string `json:"title,omitempty"`
-	Description string `json:"description,omitempty"`
-	Status      string `json:"status,omitempty"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Status      string     `json:"status"`
+	Priority    string     `json:"priority"`
+	DueAt       *time.Time `json:"due_at"`
 }
 
-// for PUT /tasks/{id}.
+// for PUT /tasks/{id}. replaces the task's title, description, status, priority
+// and due date; other fields have endpoints of their own. with If-Match, the
+// update only goes through if the task's ETag still matches, and fails with
+// 412 Precondition Failed otherwise.
 func (h *TaskHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		http.Error(w, "Could not retrieve user ID from context", http.StatusInternalServerError)
+		return
+	}
+
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
@@ -80,26 +324,157 @@ func (h *TaskHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	version, err := parseIfMatch(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	var req UpdateTaskRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
+	if err := tasks.ValidateTitle(req.Title); err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	status, err := tasks.ParseStatus(req.Status)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	priority, err := tasks.ParsePriority(req.Priority)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
 	task := &tasks.Task{
 		ID:          id,
 		Title:       req.Title,
 		Description: req.Description,
-		Status:      req.Status,
+		Status:      status,
+		Priority:    priority,
+		DueAt:       req.DueAt,
+		Version:     version,
 	}
 
-	updatedTask, err := h.taskUsecase.UpdateTask(r.Context(), task)
+	updatedTask, err := h.taskUsecase.UpdateTask(r.Context(), actor, task)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("ETag", taskETag(updatedTask))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(updatedTask)
+}
+
+// for PATCH /tasks/{id} endpoint. takes a JSON Merge Patch (application/merge-patch+json)
+// or a JSON Patch (application/json-patch+json) against the task as GET returns it.
+// fields the patch leaves alone keep their values. If-Match works as for PUT.
+func (h *TaskHandler) PatchTask(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		http.Error(w, "Could not retrieve user ID from context", http.StatusInternalServerError)
+		return
+	}
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid task ID", http.StatusBadRequest)
 		return
 	}
 
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	format := tasks.PatchFormat(mediaType)
+	if format != tasks.MergePatch && format != tasks.JSONPatch {
+		w.Header().Set("Accept-Patch", string(tasks.MergePatch)+", "+string(tasks.JSONPatch))
+		http.Error(w, "Content-Type must be application/merge-patch+json or application/json-patch+json", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	version, err := parseIfMatch(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	updatedTask, err := h.taskUsecase.PatchTask(r.Context(), actor, id, tasks.Patch{Format: format, Body: body, Version: version})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("ETag", taskETag(updatedTask))
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(updatedTask)
 }
+
+// for DELETE /tasks/{id} endpoint
+func (h *TaskHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		http.Error(w, "Could not retrieve user ID from context", http.StatusInternalServerError)
+		return
+	}
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.taskUsecase.DeleteTask(r.Context(), actor, id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// builds the actor from the user id and role the auth middleware put in the context
+func actorFromRequest(r *http.Request) (tasks.Actor, bool) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int)
+	if !ok {
+		return tasks.Actor{}, false
+	}
+	role, _ := r.Context().Value(middleware.RoleContextKey).(string) // tokens from before roles have none
+	return tasks.Actor{UserID: userID, Role: role}, true
+}
+
+// maps domain errors from the usecase layer to http status codes
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, tasks.ErrTaskNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, tasks.ErrForbidden), errors.Is(err, tasks.ErrUnverifiedUser):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, tasks.ErrInvalidUser):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, tasks.ErrInvalidStatus), errors.Is(err, tasks.ErrInvalidPriority),
+		errors.Is(err, tasks.ErrInvalidParent), errors.Is(err, tasks.ErrInvalidDependency),
+		errors.Is(err, tasks.ErrInvalidTask):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	case errors.Is(err, tasks.ErrInvalidStatusTransition), errors.Is(err, tasks.ErrTaskCycle), errors.Is(err, tasks.ErrOpenSubtasks),
+		errors.Is(err, tasks.ErrDependencyCycle), errors.Is(err, tasks.ErrOpenDependencies),
+		errors.Is(err, tasks.ErrPatchConflict):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, tasks.ErrInvalidSort), errors.Is(err, tasks.ErrInvalidCursor), errors.Is(err, tasks.ErrInvalidSearch),
+		errors.Is(err, tasks.ErrInvalidFilter), errors.Is(err, tasks.ErrInvalidPatch):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, tasks.ErrVersionMismatch):
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,263 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"task_service/internal/core/tasks"
+	"task_service/internal/core/views"
+	"task_service/internal/usecase"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type ViewHandler struct {
+	viewUsecase usecase.ViewUsecase
+}
+
+func NewViewHandler(uc usecase.ViewUsecase) *ViewHandler {
+	return &ViewHandler{
+		viewUsecase: uc,
+	}
+}
+
+type ViewRequest struct {
+	Name    string   `json:"name"`
+	Filter  string   `json:"filter"`  // same language as GET /tasks?filter=
+	Sort    string   `json:"sort"`    // same as GET /tasks?sort=
+	Columns []string `json:"columns"` // task fields, defaults to a basic set
+	TeamID  *int     `json:"team_id"` // only on create; shares the view with the team
+}
+
+// for POST /views endpoint
+func (h *ViewHandler) CreateView(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		http.Error(w, "Could not retrieve user ID from context", http.StatusInternalServerError)
+		return
+	}
+
+	var req ViewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	view := &views.View{Name: req.Name, Filter: req.Filter, Sort: req.Sort, Columns: req.Columns, TeamID: req.TeamID}
+	if err := h.viewUsecase.CreateView(r.Context(), actor, view); err != nil {
+		writeViewError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(view)
+}
+
+// for GET /views endpoint. the caller's own views and those of their teams.
+func (h *ViewHandler) ListViews(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		http.Error(w, "Could not retrieve user ID from context", http.StatusInternalServerError)
+		return
+	}
+
+	viewList, err := h.viewUsecase.ListViews(r.Context(), actor)
+	if err != nil {
+		writeViewError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(viewList)
+}
+
+// for GET /views/{id} endpoint. id can be "default" for the caller's default view.
+func (h *ViewHandler) GetView(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		http.Error(w, "Could not retrieve user ID from context", http.StatusInternalServerError)
+		return
+	}
+
+	var view *views.View
+	var err error
+	if chi.URLParam(r, "id") == "default" {
+		view, err = h.viewUsecase.GetDefaultView(r.Context(), actor)
+	} else {
+		id, convErr := strconv.Atoi(chi.URLParam(r, "id"))
+		if convErr != nil {
+			http.Error(w, "Invalid view ID", http.StatusBadRequest)
+			return
+		}
+		view, err = h.viewUsecase.GetView(r.Context(), actor, id)
+	}
+	if err != nil {
+		writeViewError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(view)
+}
+
+// for PUT /views/{id} endpoint
+func (h *ViewHandler) UpdateView(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		http.Error(w, "Could not retrieve user ID from context", http.StatusInternalServerError)
+		return
+	}
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid view ID", http.StatusBadRequest)
+		return
+	}
+
+	var req ViewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	view, err := h.viewUsecase.UpdateView(r.Context(), actor, &views.View{ID: id, Name: req.Name, Filter: req.Filter, Sort: req.Sort, Columns: req.Columns})
+	if err != nil {
+		writeViewError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(view)
+}
+
+// for DELETE /views/{id} endpoint
+func (h *ViewHandler) DeleteView(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		http.Error(w, "Could not retrieve user ID from context", http.StatusInternalServerError)
+		return
+	}
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid view ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.viewUsecase.DeleteView(r.Context(), actor, id); err != nil {
+		writeViewError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// for PUT /views/{id}/default endpoint. replaces the caller's previous default.
+func (h *ViewHandler) SetDefaultView(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		http.Error(w, "Could not retrieve user ID from context", http.StatusInternalServerError)
+		return
+	}
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid view ID", http.StatusBadRequest)
+		return
+	}
+
+	view, err := h.viewUsecase.SetDefaultView(r.Context(), actor, id)
+	if err != nil {
+		writeViewError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(view)
+}
+
+// for DELETE /views/default endpoint
+func (h *ViewHandler) ClearDefaultView(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		http.Error(w, "Could not retrieve user ID from context", http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.viewUsecase.ClearDefaultView(r.Context(), actor); err != nil {
+		writeViewError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// for GET /views/{id}/tasks endpoint. id can be "default". supports ?limit=&cursor=
+// each task holds only the view's columns, plus its id.
+func (h *ViewHandler) ListViewTasks(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		http.Error(w, "Could not retrieve user ID from context", http.StatusInternalServerError)
+		return
+	}
+
+	var id int
+	if chi.URLParam(r, "id") == "default" {
+		view, err := h.viewUsecase.GetDefaultView(r.Context(), actor)
+		if err != nil {
+			writeViewError(w, err)
+			return
+		}
+		id = view.ID
+	} else {
+		var err error
+		id, err = strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			http.Error(w, "Invalid view ID", http.StatusBadRequest)
+			return
+		}
+	}
+
+	var limit int
+	if v := r.URL.Query().Get("limit"); v != "" {
+		l, err := strconv.Atoi(v)
+		if err != nil || l < 1 || l > tasks.MaxPageSize {
+			http.Error(w, fmt.Sprintf("limit must be between 1 and %d", tasks.MaxPageSize), http.StatusBadRequest)
+			return
+		}
+		limit = l
+	}
+
+	page, err := h.viewUsecase.ListViewTasks(r.Context(), actor, id, limit, r.URL.Query().Get("cursor"))
+	if err != nil {
+		writeViewError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(page)
+}
+
+// maps view errors to http status codes; task errors are handled by writeError.
+// a bad filter or sort in a saved view is a bad body, not a bad query.
+func writeViewError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, views.ErrViewNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, views.ErrInvalidViewName), errors.Is(err, views.ErrInvalidColumns),
+		errors.Is(err, tasks.ErrInvalidFilter), errors.Is(err, tasks.ErrInvalidSort):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	case errors.Is(err, views.ErrDuplicateView):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		writeError(w, err)
+	}
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"task_service/pkg/validatejwt"
+)
+
+type contextKey string
+
+const (
+	UserIDContextKey = contextKey("userID")
+	RoleContextKey   = contextKey("role")
+)
+
+// middleware for JWT authentication, using tokens issued by the user service.
+// session revocation is only checked by the user service itself; here a revoked
+// session stays usable until its short-lived access token expires.
+func AuthMiddleware(jwtSecret string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authHeader := r.Header.Get("Authorization")
+			if authHeader == "" {
+				http.Error(w, "Authorization header required", http.StatusUnauthorized)
+				return
+			}
+
+			// header expected in the format "Bearer <token>".
+			headerParts := strings.Split(authHeader, " ")
+			if len(headerParts) != 2 || strings.ToLower(headerParts[0]) != "bearer" {
+				http.Error(w, "Invalid Authorization header format", http.StatusUnauthorized)
+				return
+			}
+
+			claims, err := validatejwt.ValidateToken(headerParts[1], jwtSecret)
+			if err != nil {
+				http.Error(w, "Invalid token: "+err.Error(), http.StatusUnauthorized)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), UserIDContextKey, claims.UserID)
+			ctx = context.WithValue(ctx, RoleContextKey, claims.Role)
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"shared/events"
+	"task_service/internal/core/comments"
+	"task_service/internal/core/tasks"
+)
+
+type commentUsecase struct {
+	commentRepo CommentRepository
+	taskUsecase TaskUsecase // access to the task is checked by loading it through here
+	userClient  UserServiceClient
+}
+
+func NewCommentUsecase(repo CommentRepository, taskUC TaskUsecase, client UserServiceClient) CommentUsecase {
+	return &commentUsecase{
+		commentRepo: repo,
+		taskUsecase: taskUC,
+		userClient:  client,
+	}
+}
+
+// @mentions are resolved to user ids when the comment is written. the comment.created
+// event goes out through the outbox like task events do.
+func (uc *commentUsecase) CreateComment(ctx context.Context, actor tasks.Actor, taskID int, body string) (*comments.Comment, error) {
+	task, err := uc.taskUsecase.GetTask(ctx, actor, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("could not create comment: %w", err)
+	}
+	comment := &comments.Comment{TaskID: task.ID, AuthorID: actor.UserID}
+	if err := uc.setBody(ctx, task, comment, body); err != nil {
+		return nil, fmt.Errorf("could not create comment: %w", err)
+	}
+
+	err = uc.commentRepo.WithTx(ctx, func(ctx context.Context) error {
+		if err := uc.commentRepo.CreateComment(ctx, comment); err != nil {
+			return err
+		}
+		return uc.commentRepo.EnqueueEvent(ctx, newCommentEvent(events.CommentCreated, actor, task, comment))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not create comment: %w", err)
+	}
+	return comment, nil
+}
+
+func (uc *commentUsecase) ListComments(ctx context.Context, actor tasks.Actor, filter comments.ListFilter) (*comments.CommentPage, error) {
+	if _, err := uc.taskUsecase.GetTask(ctx, actor, filter.TaskID); err != nil {
+		return nil, fmt.Errorf("could not list comments: %w", err)
+	}
+	page, err := uc.commentRepo.ListComments(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("could not list comments: %w", err)
+	}
+	return page, nil
+}
+
+// only the author can edit a comment
+func (uc *commentUsecase) UpdateComment(ctx context.Context, actor tasks.Actor, taskID, commentID int, body string) (*comments.Comment, error) {
+	task, err := uc.taskUsecase.GetTask(ctx, actor, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("could not update comment: %w", err)
+	}
+	comment, err := uc.commentRepo.GetComment(ctx, taskID, commentID)
+	if err != nil {
+		return nil, fmt.Errorf("could not update comment: %w", err)
+	}
+	if comment.AuthorID != actor.UserID {
+		return nil, fmt.Errorf("comment %d was written by another user: %w", comment.ID, tasks.ErrForbidden)
+	}
+	if err := uc.setBody(ctx, task, comment, body); err != nil {
+		return nil, fmt.Errorf("could not update comment: %w", err)
+	}
+	if err := uc.commentRepo.UpdateComment(ctx, comment); err != nil {
+		return nil, fmt.Errorf("could not update comment: %w", err)
+	}
+	return comment, nil
+}
+
+// the author can delete a comment, and so can whoever may delete the task
+func (uc *commentUsecase) DeleteComment(ctx context.Context, actor tasks.Actor, taskID, commentID int) error {
+	task, err := uc.taskUsecase.GetTask(ctx, actor, taskID)
+	if err != nil {
+		return fmt.Errorf("could not delete comment: %w", err)
+	}
+	comment, err := uc.commentRepo.GetComment(ctx, taskID, commentID)
+	if err != nil {
+		return fmt.Errorf("could not delete comment: %w", err)
+	}
+	if comment.AuthorID != actor.UserID && !actor.CanDelete(task) {
+		return fmt.Errorf("comment %d was written by another user: %w", comment.ID, tasks.ErrForbidden)
+	}
+	if err := uc.commentRepo.DeleteComment(ctx, taskID, commentID); err != nil {
+		return fmt.Errorf("could not delete comment: %w", err)
+	}
+	return nil
+}
+
+// validates the body and resolves its mentions. unknown usernames are not an error,
+// since "@" is also used in plain text. users who can't read the task are left
+// out, so a mention can't notify them about a task they can't open.
+func (uc *commentUsecase) setBody(ctx context.Context, task *tasks.Task, comment *comments.Comment, body string) error {
+	body, err := comments.NormalizeBody(body)
+	if err != nil {
+		return err
+	}
+	comment.Body = body
+	comment.Mentions = []int{}
+
+	usernames := comments.ParseMentions(body)
+	if len(usernames) == 0 {
+		return nil
+	}
+	res, err := uc.userClient.GetUsersByUsernames(ctx, usernames)
+	if err != nil {
+		return fmt.Errorf("could not resolve mentions: %w", err)
+	}
+	for _, user := range res.GetUsers() {
+		reader := tasks.Actor{UserID: int(user.GetId()), Role: user.GetRole()}
+		canRead, err := uc.canRead(ctx, reader, task)
+		if err != nil {
+			return fmt.Errorf("could not resolve mentions: %w", err)
+		}
+		if canRead {
+			comment.Mentions = append(comment.Mentions, reader.UserID)
+		}
+	}
+	return nil
+}
+
+// like the check on GetTask, for a user other than the caller
+func (uc *commentUsecase) canRead(ctx context.Context, user tasks.Actor, task *tasks.Task) (bool, error) {
+	if user.CanRead(task) {
+		return true, nil
+	}
+	if task.TeamID == nil {
+		return false, nil
+	}
+	res, err := uc.userClient.GetTeamMembership(ctx, int32(*task.TeamID), int32(user.UserID))
+	if err != nil {
+		return false, fmt.Errorf("could not check team membership: %w", err)
+	}
+	return res.GetIsMember(), nil
+}
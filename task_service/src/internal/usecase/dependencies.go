@@ -0,0 +1,147 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"shared/events"
+	"task_service/internal/core/tasks"
+)
+
+// the caller must be able to edit the task and read its new prerequisite.
+// the task is blocked right away if the prerequisite is still open.
+func (uc *taskUsecase) AddDependency(ctx context.Context, actor tasks.Actor, taskID, dependsOnID int) (*tasks.Task, error) {
+	if taskID == dependsOnID {
+		return nil, fmt.Errorf("task %d can't depend on itself: %w", taskID, tasks.ErrDependencyCycle)
+	}
+	var updatedTask *tasks.Task
+	err := uc.taskRepo.WithTx(ctx, func(ctx context.Context) error {
+		if err := uc.taskRepo.LockTaskDependencies(ctx); err != nil {
+			return err
+		}
+		current, err := uc.taskRepo.GetTaskForUpdate(ctx, taskID)
+		if err != nil {
+			return err
+		}
+		if err := checkCanEdit(actor, current); err != nil {
+			return err
+		}
+		_, err = uc.getReadableTask(ctx, actor, dependsOnID)
+		if errors.Is(err, tasks.ErrTaskNotFound) {
+			return fmt.Errorf("%w: task %d does not exist", tasks.ErrInvalidDependency, dependsOnID)
+		}
+		if err != nil {
+			return err
+		}
+		cycle, err := uc.taskRepo.DependsOn(ctx, dependsOnID, taskID)
+		if err != nil {
+			return err
+		}
+		if cycle {
+			return fmt.Errorf("task %d already depends on task %d: %w", dependsOnID, taskID, tasks.ErrDependencyCycle)
+		}
+
+		if err := uc.taskRepo.AddDependency(ctx, taskID, dependsOnID); err != nil {
+			return err
+		}
+		updatedTask, err = uc.syncBlocked(ctx, actor, current)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not add dependency: %w", err)
+	}
+	return updatedTask, nil
+}
+
+// the task is unblocked if this was its last open prerequisite
+func (uc *taskUsecase) RemoveDependency(ctx context.Context, actor tasks.Actor, taskID, dependsOnID int) (*tasks.Task, error) {
+	var updatedTask *tasks.Task
+	err := uc.taskRepo.WithTx(ctx, func(ctx context.Context) error {
+		current, err := uc.taskRepo.GetTaskForUpdate(ctx, taskID)
+		if err != nil {
+			return err
+		}
+		if err := checkCanEdit(actor, current); err != nil {
+			return err
+		}
+		if err := uc.taskRepo.RemoveDependency(ctx, taskID, dependsOnID); err != nil {
+			return err
+		}
+		updatedTask, err = uc.syncBlocked(ctx, actor, current)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not remove dependency: %w", err)
+	}
+	return updatedTask, nil
+}
+
+// a task with open prerequisites can only be blocked or closed
+func (uc *taskUsecase) checkPrerequisites(ctx context.Context, task *tasks.Task, next tasks.Status) error {
+	if next == tasks.StatusBlocked || next.IsClosed() || len(task.BlockedBy) == 0 {
+		return nil
+	}
+	open, err := uc.taskRepo.CountOpenPrerequisites(ctx, task.ID)
+	if err != nil {
+		return err
+	}
+	if open > 0 {
+		return fmt.Errorf("task %d waits for %d open prerequisite(s): %w", task.ID, open, tasks.ErrOpenDependencies)
+	}
+	return nil
+}
+
+// re-evaluates every task depending on taskID, after taskID was closed, reopened or deleted
+func (uc *taskUsecase) syncDependents(ctx context.Context, actor tasks.Actor, dependentIDs []int) error {
+	for _, id := range dependentIDs {
+		current, err := uc.taskRepo.GetTaskForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if _, err := uc.syncBlocked(ctx, actor, current); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// blocks an open task that has open prerequisites, and unblocks a task blocked that
+// way once it has none left. current is the task as locked before the change that
+// triggered this; one event describing everything that changed since is enqueued.
+// tasks blocked by hand are left alone.
+func (uc *taskUsecase) syncBlocked(ctx context.Context, actor tasks.Actor, current *tasks.Task) (*tasks.Task, error) {
+	open, err := uc.taskRepo.CountOpenPrerequisites(ctx, current.ID)
+	if err != nil {
+		return nil, err
+	}
+	// the status may have changed since current was loaded, so look at it again
+	task, err := uc.taskRepo.GetTask(ctx, current.ID)
+	if err != nil {
+		return nil, err
+	}
+	unblocked := false
+	switch {
+	case open > 0 && !task.Status.IsClosed() && task.Status != tasks.StatusBlocked:
+		if err := uc.taskRepo.SetBlockedStatus(ctx, task.ID, tasks.StatusBlocked, task.Status); err != nil {
+			return nil, err
+		}
+	case open == 0 && task.Status == tasks.StatusBlocked && task.BlockedFrom != "":
+		if err := uc.taskRepo.SetBlockedStatus(ctx, task.ID, task.UnblockedStatus(), ""); err != nil {
+			return nil, err
+		}
+		unblocked = true
+	}
+
+	updated, err := uc.taskRepo.GetTask(ctx, current.ID)
+	if err != nil {
+		return nil, err
+	}
+	event := newTaskUpdatedEvent(actor, current, updated)
+	if unblocked {
+		event.Type = events.TaskUnblocked
+	}
+	if len(event.Changes) == 0 {
+		return updated, nil
+	}
+	return updated, uc.taskRepo.EnqueueEvent(ctx, event)
+}
@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"shared/events"
+	"strings"
+	"task_service/internal/core/comments"
+	"task_service/internal/core/labels"
+	"task_service/internal/core/tasks"
+	"time"
+)
+
+func toSnapshot(task *tasks.Task) events.TaskSnapshot {
+	return events.TaskSnapshot{
+		ID:          task.ID,
+		Title:       task.Title,
+		Description: task.Description,
+		Status:      string(task.Status),
+		Priority:    string(task.Priority),
+		DueAt:       task.DueAt,
+		UserID:      task.UserID,
+		TeamID:      task.TeamID,
+		ParentID:    task.ParentID,
+		BlockedBy:   task.BlockedBy,
+		Labels:      labelNames(task.Labels),
+		Assignees:   task.Assignees,
+		Watchers:    task.Watchers,
+		CreatedAt:   task.CreatedAt,
+		UpdatedAt:   task.UpdatedAt,
+	}
+}
+
+func newTaskEvent(eventType events.Type, actor tasks.Actor, task *tasks.Task) *events.Event {
+	return events.New(eventType, events.Actor{UserID: actor.UserID}, toSnapshot(task))
+}
+
+// comment event carrying the task the comment belongs to, so consumers know its watchers
+func newCommentEvent(eventType events.Type, actor tasks.Actor, task *tasks.Task, comment *comments.Comment) *events.Event {
+	e := newTaskEvent(eventType, actor, task)
+	e.Comment = &events.Comment{
+		ID:       comment.ID,
+		AuthorID: comment.AuthorID,
+		Body:     comment.Body,
+		Mentions: comment.Mentions,
+	}
+	return e
+}
+
+// task.updated event carrying before/after values of every field that changed
+func newTaskUpdatedEvent(actor tasks.Actor, before, after *tasks.Task) *events.Event {
+	e := newTaskEvent(events.TaskUpdated, actor, after)
+	e.Changes = diffTasks(before, after)
+	return e
+}
+
+func diffTasks(before, after *tasks.Task) []events.FieldChange {
+	var changes []events.FieldChange
+	add := func(field string, b, a interface{}) {
+		changes = append(changes, events.FieldChange{Field: field, Before: b, After: a})
+	}
+	if before.Title != after.Title {
+		add("title", before.Title, after.Title)
+	}
+	if before.Description != after.Description {
+		add("description", before.Description, after.Description)
+	}
+	if before.Status != after.Status {
+		add("status", before.Status, after.Status)
+	}
+	if before.Priority != after.Priority {
+		add("priority", before.Priority, after.Priority)
+	}
+	if !sameTime(before.DueAt, after.DueAt) {
+		add("due_at", before.DueAt, after.DueAt)
+	}
+	if !sameID(before.ParentID, after.ParentID) {
+		add("parent_id", before.ParentID, after.ParentID)
+	}
+	if !sameIDs(before.BlockedBy, after.BlockedBy) {
+		add("blocked_by", before.BlockedBy, after.BlockedBy)
+	}
+	if b, a := labelNames(before.Labels), labelNames(after.Labels); strings.Join(b, ",") != strings.Join(a, ",") {
+		add("labels", b, a)
+	}
+	if !sameIDs(before.Assignees, after.Assignees) {
+		add("assignees", before.Assignees, after.Assignees)
+	}
+	if !sameIDs(before.Watchers, after.Watchers) {
+		add("watchers", before.Watchers, after.Watchers)
+	}
+	return changes
+}
+
+// label names can't contain commas, so joined names compare safely
+func labelNames(taskLabels []labels.Label) []string {
+	names := make([]string, len(taskLabels))
+	for i, l := range taskLabels {
+		names[i] = l.Name
+	}
+	return names
+}
+
+func sameID(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// id lists are always loaded sorted, so comparing in order is enough
+func sameIDs(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
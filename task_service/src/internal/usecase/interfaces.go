@@ -2,32 +2,163 @@ package usecase
 
 import (
 	"context"
+	"shared/events"
+	"task_service/internal/core/comments"
+	"task_service/internal/core/labels"
+	"task_service/internal/core/outbox"
 	"task_service/internal/core/tasks"
+	"task_service/internal/core/views"
 	pb "task_service/proto"
+	"time"
 )
 
-// business logic for tasks
+// business logic for tasks. every call is made on behalf of an authenticated actor.
 type TaskUsecase interface {
-	CreateTask(ctx context.Context, task *tasks.Task) error
-	ListTasks(ctx context.Context, userID, status string) ([]tasks.Task, error)
-	UpdateTask(ctx context.Context, task *tasks.Task) (*tasks.Task, error)
+	CreateTask(ctx context.Context, actor tasks.Actor, task *tasks.Task) error
+	GetTask(ctx context.Context, actor tasks.Actor, id int) (*tasks.Task, error)
+	ListTasks(ctx context.Context, actor tasks.Actor, filter tasks.ListFilter) (*tasks.TaskPage, error)
+	UpdateTask(ctx context.Context, actor tasks.Actor, task *tasks.Task) (*tasks.Task, error)
+	// applies the patch to the latest state of the task, under the same checks as UpdateTask
+	PatchTask(ctx context.Context, actor tasks.Actor, id int, patch tasks.Patch) (*tasks.Task, error)
+	DeleteTask(ctx context.Context, actor tasks.Actor, id int) error
+	AddPeople(ctx context.Context, actor tasks.Actor, taskID int, relation tasks.Relation, userIDs []int) (*tasks.Task, error)
+	RemovePerson(ctx context.Context, actor tasks.Actor, taskID int, relation tasks.Relation, userID int) (*tasks.Task, error)
+	// moves the task under parentID, or to the top level when parentID is nil
+	SetParent(ctx context.Context, actor tasks.Actor, taskID int, parentID *int) (*tasks.Task, error)
+	ListSubtasks(ctx context.Context, actor tasks.Actor, taskID int, filter tasks.ListFilter) (*tasks.TaskPage, error)
+	// makes taskID depend on dependsOnID, blocking it while dependsOnID is open
+	AddDependency(ctx context.Context, actor tasks.Actor, taskID, dependsOnID int) (*tasks.Task, error)
+	RemoveDependency(ctx context.Context, actor tasks.Actor, taskID, dependsOnID int) (*tasks.Task, error)
+}
+
+// business logic for comments on tasks. whoever can read a task can read and write its comments.
+type CommentUsecase interface {
+	CreateComment(ctx context.Context, actor tasks.Actor, taskID int, body string) (*comments.Comment, error)
+	ListComments(ctx context.Context, actor tasks.Actor, filter comments.ListFilter) (*comments.CommentPage, error)
+	UpdateComment(ctx context.Context, actor tasks.Actor, taskID, commentID int, body string) (*comments.Comment, error)
+	DeleteComment(ctx context.Context, actor tasks.Actor, taskID, commentID int) error
+}
+
+// business logic for labels and for putting them on tasks
+type LabelUsecase interface {
+	CreateLabel(ctx context.Context, actor tasks.Actor, label *labels.Label) error
+	ListLabels(ctx context.Context, actor tasks.Actor, teamID int) ([]labels.Label, error)
+	UpdateLabel(ctx context.Context, actor tasks.Actor, label *labels.Label) (*labels.Label, error)
+	DeleteLabel(ctx context.Context, actor tasks.Actor, id int) error
+	AttachLabel(ctx context.Context, actor tasks.Actor, taskID, labelID int) (*tasks.Task, error)
+	DetachLabel(ctx context.Context, actor tasks.Actor, taskID, labelID int) (*tasks.Task, error)
+}
+
+// business logic for saved views. views are seen by their owner or by the members of their team.
+type ViewUsecase interface {
+	CreateView(ctx context.Context, actor tasks.Actor, view *views.View) error
+	GetView(ctx context.Context, actor tasks.Actor, id int) (*views.View, error)
+	// the caller's default view, or views.ErrViewNotFound if they haven't picked one
+	GetDefaultView(ctx context.Context, actor tasks.Actor) (*views.View, error)
+	// the caller's personal views and those of every team they belong to
+	ListViews(ctx context.Context, actor tasks.Actor) ([]views.View, error)
+	UpdateView(ctx context.Context, actor tasks.Actor, view *views.View) (*views.View, error)
+	DeleteView(ctx context.Context, actor tasks.Actor, id int) error
+	SetDefaultView(ctx context.Context, actor tasks.Actor, id int) (*views.View, error)
+	ClearDefaultView(ctx context.Context, actor tasks.Actor) error
+	// runs the view's query for the caller. cursor comes from a previous page of the same view.
+	// runs the view, returning only its columns of each task
+	ListViewTasks(ctx context.Context, actor tasks.Actor, id int, limit int, cursor string) (*views.TaskPage, error)
+}
+
+// runs fn in a database transaction. repository calls made with the ctx passed to fn join it.
+type Transactor interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
 }
 
 // persistence operations for tasks
 type TaskRepository interface {
+	Transactor
 	CreateTask(ctx context.Context, task *tasks.Task) error
-	ListTasks(ctx context.Context, userID, status string) ([]tasks.Task, error)
-	UpdateTask(ctx context.Context, task *tasks.Task) (*tasks.Task, error)
+	GetTask(ctx context.Context, id int) (*tasks.Task, error)
+	GetTaskForUpdate(ctx context.Context, id int) (*tasks.Task, error)
+	ListTasks(ctx context.Context, filter tasks.ListFilter) (*tasks.TaskPage, error)
+	// writes the fields PUT and PATCH change, empty ones included
+	ReplaceTask(ctx context.Context, task *tasks.Task) (*tasks.Task, error)
+	DeleteTask(ctx context.Context, id int) error
+	AddTaskPeople(ctx context.Context, taskID int, relation tasks.Relation, userIDs []int) error
+	RemoveTaskPerson(ctx context.Context, taskID int, relation tasks.Relation, userID int) error
+	LockTaskHierarchy(ctx context.Context) error
+	ListAncestorIDs(ctx context.Context, id int) ([]int, error)
+	SetTaskParent(ctx context.Context, id int, parentID *int) error
+	CountOpenSubtasks(ctx context.Context, id int) (int, error)
+	LockTaskDependencies(ctx context.Context) error
+	AddDependency(ctx context.Context, taskID, dependsOnID int) error
+	RemoveDependency(ctx context.Context, taskID, dependsOnID int) error
+	DependsOn(ctx context.Context, taskID, otherID int) (bool, error)
+	CountOpenPrerequisites(ctx context.Context, id int) (int, error)
+	SetBlockedStatus(ctx context.Context, id int, status tasks.Status, blockedFrom tasks.Status) error
+	// writes the event to the outbox; call it in the same transaction as the change it describes
+	EnqueueEvent(ctx context.Context, event *events.Event) error
+}
+
+// persistence operations for comments
+type CommentRepository interface {
+	Transactor
+	CreateComment(ctx context.Context, comment *comments.Comment) error
+	GetComment(ctx context.Context, taskID, id int) (*comments.Comment, error)
+	ListComments(ctx context.Context, filter comments.ListFilter) (*comments.CommentPage, error)
+	UpdateComment(ctx context.Context, comment *comments.Comment) error
+	DeleteComment(ctx context.Context, taskID, id int) error
+	EnqueueEvent(ctx context.Context, event *events.Event) error
+}
+
+// persistence operations for labels
+type LabelRepository interface {
+	CreateLabel(ctx context.Context, label *labels.Label) error
+	GetLabel(ctx context.Context, id int) (*labels.Label, error)
+	// the labels of ownerID, or of teamID when it isn't 0
+	ListLabels(ctx context.Context, ownerID, teamID int) ([]labels.Label, error)
+	UpdateLabel(ctx context.Context, label *labels.Label) error
+	DeleteLabel(ctx context.Context, id int) error
+	AttachLabel(ctx context.Context, taskID, labelID int) error
+	DetachLabel(ctx context.Context, taskID, labelID int) error
+}
+
+// persistence operations for saved views. userID is whoever IsDefault is reported for.
+type ViewRepository interface {
+	CreateView(ctx context.Context, view *views.View) error
+	GetView(ctx context.Context, id, userID int) (*views.View, error)
+	ListViews(ctx context.Context, userID int, teamIDs []int) ([]views.View, error)
+	UpdateView(ctx context.Context, view *views.View, userID int) error
+	DeleteView(ctx context.Context, id int) error
+	SetDefaultView(ctx context.Context, userID, viewID int) error
+	ClearDefaultView(ctx context.Context, userID int) error
+	GetDefaultViewID(ctx context.Context, userID int) (int, error)
+}
+
+// persistence operations used by the outbox relay
+type OutboxRepository interface {
+	Transactor
+	TryLockOutbox(ctx context.Context) (bool, error)
+	NextOutboxBatch(ctx context.Context, limit int) ([]outbox.Record, error)
+	DeleteOutboxRecord(ctx context.Context, id int64) error
+	RescheduleOutboxRecord(ctx context.Context, id int64, nextAttemptAt time.Time, lastError string) error
+	OutboxBacklog(ctx context.Context) (outbox.Backlog, error)
 }
 
 // for communicating with the User Service
 type UserServiceClient interface {
 	GetUser(ctx context.Context, userID int32) (*pb.GetUserResponse, error)
+	BatchGetUsers(ctx context.Context, userIDs []int32) (*pb.BatchGetUsersResponse, error)
+	ValidateUsers(ctx context.Context, userIDs []int32) (*pb.ValidateUsersResponse, error)
+	GetUsersByUsernames(ctx context.Context, usernames []string) (*pb.GetUsersByUsernamesResponse, error)
+	GetTeamMembership(ctx context.Context, teamID, userID int32) (*pb.GetTeamMembershipResponse, error)
+	ListUserTeams(ctx context.Context, userID int32) (*pb.ListUserTeamsResponse, error)
 }
 
 // for the caching layer
 type Cache interface {
-	SetUserValidation(ctx context.Context, userID int32) error
-	GetUserValidation(ctx context.Context, userID int32) (bool, error)
-	PublishTaskNotification(ctx context.Context, message string) error
+	SetUserValidation(ctx context.Context, userID int32, emailVerified bool) error
+	GetUserValidation(ctx context.Context, userID int32) (validated, emailVerified bool, err error)
+}
+
+// the message bus task events are relayed to
+type EventPublisher interface {
+	PublishTaskEvent(ctx context.Context, payload []byte) error
 }
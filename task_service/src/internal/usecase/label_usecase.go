@@ -0,0 +1,202 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"task_service/internal/core/labels"
+	"task_service/internal/core/tasks"
+)
+
+type labelUsecase struct {
+	labelRepo  LabelRepository
+	taskRepo   TaskRepository
+	userClient UserServiceClient
+}
+
+func NewLabelUsecase(labelRepo LabelRepository, taskRepo TaskRepository, client UserServiceClient) LabelUsecase {
+	return &labelUsecase{
+		labelRepo:  labelRepo,
+		taskRepo:   taskRepo,
+		userClient: client,
+	}
+}
+
+// Scoping rules: a personal label is only seen, used and changed by its owner.
+// A team label is seen and used by every member of the team, on the team's
+// tasks only, and changed by the team's owners and admins.
+
+// with label.TeamID set the label is created for that team, otherwise for the caller
+func (uc *labelUsecase) CreateLabel(ctx context.Context, actor tasks.Actor, label *labels.Label) error {
+	if err := normalizeLabel(label); err != nil {
+		return err
+	}
+	label.OwnerID = nil
+	if label.TeamID != nil {
+		if _, err := teamRole(ctx, uc.userClient, actor, *label.TeamID); err != nil {
+			return fmt.Errorf("could not create label: %w", err)
+		}
+	} else {
+		label.OwnerID = &actor.UserID
+	}
+	if err := uc.labelRepo.CreateLabel(ctx, label); err != nil {
+		return fmt.Errorf("could not create label: %w", err)
+	}
+	return nil
+}
+
+// the caller's own labels, or those of a team they belong to
+func (uc *labelUsecase) ListLabels(ctx context.Context, actor tasks.Actor, teamID int) ([]labels.Label, error) {
+	if teamID != 0 && !actor.CanReadAll() {
+		if _, err := teamRole(ctx, uc.userClient, actor, teamID); err != nil {
+			return nil, fmt.Errorf("could not list labels of team %d: %w", teamID, err)
+		}
+	}
+	labelList, err := uc.labelRepo.ListLabels(ctx, actor.UserID, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("could not list labels: %w", err)
+	}
+	return labelList, nil
+}
+
+// renames and recolors a label; tasks carrying it pick the change up. no
+// task.updated events are published for them, see events.TaskUpdated.
+func (uc *labelUsecase) UpdateLabel(ctx context.Context, actor tasks.Actor, label *labels.Label) (*labels.Label, error) {
+	current, err := uc.labelRepo.GetLabel(ctx, label.ID)
+	if err != nil {
+		return nil, fmt.Errorf("could not update label: %w", err)
+	}
+	if err := uc.checkCanManage(ctx, actor, current); err != nil {
+		return nil, fmt.Errorf("could not update label: %w", err)
+	}
+	if err := normalizeLabel(label); err != nil {
+		return nil, err
+	}
+	if err := uc.labelRepo.UpdateLabel(ctx, label); err != nil {
+		return nil, fmt.Errorf("could not update label: %w", err)
+	}
+	return label, nil
+}
+
+// detaches the label from every task carrying it, without publishing
+// task.updated events for them, as with UpdateLabel
+func (uc *labelUsecase) DeleteLabel(ctx context.Context, actor tasks.Actor, id int) error {
+	label, err := uc.labelRepo.GetLabel(ctx, id)
+	if err != nil {
+		return fmt.Errorf("could not delete label: %w", err)
+	}
+	if err := uc.checkCanManage(ctx, actor, label); err != nil {
+		return fmt.Errorf("could not delete label: %w", err)
+	}
+	if err := uc.labelRepo.DeleteLabel(ctx, id); err != nil {
+		return fmt.Errorf("could not delete label: %w", err)
+	}
+	return nil
+}
+
+func (uc *labelUsecase) AttachLabel(ctx context.Context, actor tasks.Actor, taskID, labelID int) (*tasks.Task, error) {
+	label, err := uc.labelRepo.GetLabel(ctx, labelID)
+	if err != nil {
+		return nil, fmt.Errorf("could not attach label: %w", err)
+	}
+	task, err := uc.changeLabels(ctx, actor, taskID, func(ctx context.Context, task *tasks.Task) error {
+		if err := uc.checkCanUse(ctx, actor, label, task); err != nil {
+			return err
+		}
+		return uc.labelRepo.AttachLabel(ctx, taskID, labelID)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not attach label: %w", err)
+	}
+	return task, nil
+}
+
+// anyone who can edit the task can take any label off it
+func (uc *labelUsecase) DetachLabel(ctx context.Context, actor tasks.Actor, taskID, labelID int) (*tasks.Task, error) {
+	task, err := uc.changeLabels(ctx, actor, taskID, func(ctx context.Context, task *tasks.Task) error {
+		return uc.labelRepo.DetachLabel(ctx, taskID, labelID)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not detach label: %w", err)
+	}
+	return task, nil
+}
+
+// runs change on the locked, editable task and publishes the resulting update
+func (uc *labelUsecase) changeLabels(ctx context.Context, actor tasks.Actor, taskID int, change func(ctx context.Context, task *tasks.Task) error) (*tasks.Task, error) {
+	var updatedTask *tasks.Task
+	err := uc.taskRepo.WithTx(ctx, func(ctx context.Context) error {
+		current, err := uc.taskRepo.GetTaskForUpdate(ctx, taskID)
+		if err != nil {
+			return err
+		}
+		if err := checkCanEdit(actor, current); err != nil {
+			return err
+		}
+		if err := change(ctx, current); err != nil {
+			return err
+		}
+		updatedTask, err = uc.taskRepo.GetTask(ctx, taskID)
+		if err != nil {
+			return err
+		}
+		event := newTaskUpdatedEvent(actor, current, updatedTask)
+		if len(event.Changes) == 0 {
+			return nil
+		}
+		return uc.taskRepo.EnqueueEvent(ctx, event)
+	})
+	return updatedTask, err
+}
+
+func (uc *labelUsecase) checkCanUse(ctx context.Context, actor tasks.Actor, label *labels.Label, task *tasks.Task) error {
+	if label.TeamID == nil {
+		if *label.OwnerID != actor.UserID && !actor.CanEditAll() {
+			return fmt.Errorf("label %d belongs to another user: %w", label.ID, labels.ErrLabelNotFound)
+		}
+		return nil
+	}
+	if task.TeamID == nil || *task.TeamID != *label.TeamID {
+		return fmt.Errorf("label %d is only for tasks of team %d: %w", label.ID, *label.TeamID, labels.ErrLabelNotUsable)
+	}
+	if actor.CanEditAll() {
+		return nil
+	}
+	if _, err := teamRole(ctx, uc.userClient, actor, *label.TeamID); err != nil {
+		return fmt.Errorf("label %d: %w", label.ID, labels.ErrLabelNotFound)
+	}
+	return nil
+}
+
+func (uc *labelUsecase) checkCanManage(ctx context.Context, actor tasks.Actor, label *labels.Label) error {
+	if actor.CanEditAll() {
+		return nil
+	}
+	if label.TeamID == nil {
+		if *label.OwnerID != actor.UserID {
+			// other users' labels are invisible, not forbidden
+			return fmt.Errorf("label %d belongs to another user: %w", label.ID, labels.ErrLabelNotFound)
+		}
+		return nil
+	}
+	role, err := teamRole(ctx, uc.userClient, actor, *label.TeamID)
+	if err != nil {
+		return fmt.Errorf("label %d: %w", label.ID, labels.ErrLabelNotFound)
+	}
+	if role != teamRoleOwner && role != teamRoleAdmin {
+		return fmt.Errorf("only team owners and admins can change label %d: %w", label.ID, tasks.ErrForbidden)
+	}
+	return nil
+}
+
+func normalizeLabel(label *labels.Label) error {
+	name, err := labels.NormalizeName(label.Name)
+	if err != nil {
+		return err
+	}
+	color, err := labels.ParseColor(label.Color)
+	if err != nil {
+		return err
+	}
+	label.Name, label.Color = name, color
+	return nil
+}
@@ -0,0 +1,126 @@
+package usecase
+
+import (
+	"context"
+	"expvar"
+	"log"
+	"time"
+)
+
+// relay metrics, served on /debug/vars
+var (
+	outboxPublished      = expvar.NewInt("outbox_published_total")
+	outboxPublishFailure = expvar.NewInt("outbox_publish_failures_total")
+	outboxBacklogSize    = expvar.NewInt("outbox_backlog_size")
+	outboxOldestAge      = expvar.NewFloat("outbox_oldest_age_seconds")
+)
+
+type OutboxRelayConfig struct {
+	PollInterval time.Duration // pause between drains when the outbox is empty
+	BatchSize    int           // records fetched per round
+	MinBackoff   time.Duration // first retry delay after a failed publish, doubled per attempt
+	MaxBackoff   time.Duration
+}
+
+// moves events from the outbox table to the message bus. delivery is at
+// least once: an event can be published again if the commit that removes it fails.
+type OutboxRelay struct {
+	repo      OutboxRepository
+	publisher EventPublisher
+	cfg       OutboxRelayConfig
+}
+
+func NewOutboxRelay(repo OutboxRepository, publisher EventPublisher, cfg OutboxRelayConfig) *OutboxRelay {
+	return &OutboxRelay{repo: repo, publisher: publisher, cfg: cfg}
+}
+
+// drains the outbox until ctx is cancelled
+func (r *OutboxRelay) Run(ctx context.Context) {
+	log.Printf("Outbox relay started, polling every %s", r.cfg.PollInterval)
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		r.drain(ctx)
+		r.updateBacklogMetrics(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// publishes rounds of records until nothing is due
+func (r *OutboxRelay) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		published, err := r.relayBatch(ctx)
+		if err != nil {
+			log.Printf("Outbox relay error: %v", err)
+			return
+		}
+		if published == 0 {
+			return
+		}
+	}
+}
+
+// one round: at most one record per task, so a failing event holds back the
+// later events of its task but not of other tasks.
+func (r *OutboxRelay) relayBatch(ctx context.Context) (int, error) {
+	published := 0
+	err := r.repo.WithTx(ctx, func(ctx context.Context) error {
+		locked, err := r.repo.TryLockOutbox(ctx)
+		if err != nil || !locked {
+			return err // another replica is draining
+		}
+		records, err := r.repo.NextOutboxBatch(ctx, r.cfg.BatchSize)
+		if err != nil {
+			return err
+		}
+		for _, rec := range records {
+			if err := r.publisher.PublishTaskEvent(ctx, rec.Payload); err != nil {
+				outboxPublishFailure.Add(1)
+				next := time.Now().Add(r.backoff(rec.Attempts + 1))
+				log.Printf("Could not publish event %s (attempt %d), retrying at %s: %v", rec.EventID, rec.Attempts+1, next.Format(time.RFC3339), err)
+				if err := r.repo.RescheduleOutboxRecord(ctx, rec.ID, next, err.Error()); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := r.repo.DeleteOutboxRecord(ctx, rec.ID); err != nil {
+				return err
+			}
+			outboxPublished.Add(1)
+			published++
+		}
+		return nil
+	})
+	return published, err
+}
+
+func (r *OutboxRelay) backoff(attempt int) time.Duration {
+	d := r.cfg.MinBackoff
+	for i := 1; i < attempt && d < r.cfg.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > r.cfg.MaxBackoff {
+		d = r.cfg.MaxBackoff
+	}
+	return d
+}
+
+func (r *OutboxRelay) updateBacklogMetrics(ctx context.Context) {
+	backlog, err := r.repo.OutboxBacklog(ctx)
+	if err != nil {
+		log.Printf("Could not read outbox backlog: %v", err)
+		return
+	}
+	outboxBacklogSize.Set(int64(backlog.Size))
+	age := 0.0
+	if backlog.Oldest != nil {
+		age = time.Since(*backlog.Oldest).Seconds()
+	}
+	outboxOldestAge.Set(age)
+}
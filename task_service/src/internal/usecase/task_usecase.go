@@ -2,8 +2,10 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"shared/events"
 	"task_service/internal/core/tasks"
 )
 
@@ -11,71 +13,549 @@ type taskUsecase struct {
 	taskRepo   TaskRepository
 	userClient UserServiceClient
 	cache      Cache
+	cfg        TaskUsecaseConfig
 }
 
-func NewTaskUsecase(repo TaskRepository, client UserServiceClient, cache Cache) TaskUsecase {
+type TaskUsecaseConfig struct {
+	// tasks can only be assigned to users who confirmed their email address
+	RequireVerifiedUsers bool
+	// a task can't be marked done while it has subtasks that are still open
+	RequireClosedSubtasks bool
+}
+
+func NewTaskUsecase(repo TaskRepository, client UserServiceClient, cache Cache, cfg TaskUsecaseConfig) TaskUsecase {
 	return &taskUsecase{
 		taskRepo:   repo,
 		userClient: client,
 		cache:      cache,
+		cfg:        cfg,
 	}
 }
 
+// Ownership rules: a task belongs to the user who created it. Operations on a
+// task id that doesn't exist fail with tasks.ErrTaskNotFound (404), operations
+// on a task owned by someone else fail with tasks.ErrForbidden (403).
+// Managers may read every task and admins may also change or delete them.
+// A task shared with a team is also visible to the team's members, who are
+// looked up in the user service; changing it is still up to its owner.
+// Assignees may change a task and watchers may read it; only the owner (or an
+// admin) may delete it or manage who is assigned.
+
 // to checkk the cache before making a grpc call
-func (uc *taskUsecase) CreateTask(ctx context.Context, task *tasks.Task) error {
+// assignees and watchers may be set on the task; without assignees the caller is assigned.
+// a subtask can only be created under a parent the caller may edit.
+func (uc *taskUsecase) CreateTask(ctx context.Context, actor tasks.Actor, task *tasks.Task) error {
+	task.UserID = actor.UserID // tasks are always created for the caller
+	if task.TeamID != nil && !actor.CanEditAll() {
+		if err := uc.checkTeamMember(ctx, actor, *task.TeamID); err != nil {
+			return fmt.Errorf("could not create task: %w", err)
+		}
+	}
+	assignees, watchers := task.Assignees, task.Watchers
+	if len(assignees) == 0 {
+		assignees = []int{actor.UserID}
+	}
+	if err := uc.validatePeople(ctx, tasks.RelationAssignee, withoutID(assignees, actor.UserID)); err != nil {
+		return fmt.Errorf("could not create task: %w", err)
+	}
+	if err := uc.validatePeople(ctx, tasks.RelationWatcher, withoutID(watchers, actor.UserID)); err != nil {
+		return fmt.Errorf("could not create task: %w", err)
+	}
+
 	// checking if the user is already validated in the cache.
-	isValidated, err := uc.cache.GetUserValidation(ctx, int32(task.UserID))
+	isValidated, isVerified, err := uc.cache.GetUserValidation(ctx, int32(task.UserID))
 	if err != nil {
 		log.Printf("Cache error: %v", err)
 	}
+	// an unverified user may have verified since they were cached, so ask again
+	if uc.cfg.RequireVerifiedUsers && !isVerified {
+		isValidated = false
+	}
 
 	if isValidated {
 		log.Printf("Cache HIT for user ID: %d", task.UserID)
 	} else {
 		log.Printf("Cache MISS for user ID: %d. Calling User Service.", task.UserID)
 		// if it reacxhes here, it means it is not in cache, so we'll validate the user via grpc
-		_, err := uc.userClient.GetUser(ctx, int32(task.UserID))
+		user, err := uc.userClient.GetUser(ctx, int32(task.UserID))
 		if err != nil {
-			return fmt.Errorf("invalid user ID: %w", err)
+			return fmt.Errorf("%w: %v", tasks.ErrInvalidUser, err)
+		}
+		if uc.cfg.RequireVerifiedUsers && !user.GetEmailVerified() {
+			return fmt.Errorf("could not create task for user %d: %w", task.UserID, tasks.ErrUnverifiedUser)
 		}
 
 		// if the user is valid, store the validation in the cache for next time.
-		if err := uc.cache.SetUserValidation(ctx, int32(task.UserID)); err != nil {
+		// the verified flag is cached too, since RequireVerifiedUsers may be off
+		// when the entry is written and on when it is read.
+		if err := uc.cache.SetUserValidation(ctx, int32(task.UserID), user.GetEmailVerified()); err != nil {
 			log.Printf("Could not set user validation in cache: %v", err)
 		}
 	}
 
-	err = uc.taskRepo.CreateTask(ctx, task) //create task in database
+	// the task and its event are committed together; the outbox relay publishes the event
+	err = uc.taskRepo.WithTx(ctx, func(ctx context.Context) error {
+		if task.ParentID != nil {
+			if _, err := uc.getParent(ctx, actor, *task.ParentID); err != nil {
+				return err
+			}
+		}
+		if err := uc.taskRepo.CreateTask(ctx, task); err != nil {
+			return err
+		}
+		if err := uc.taskRepo.AddTaskPeople(ctx, task.ID, tasks.RelationAssignee, assignees); err != nil {
+			return err
+		}
+		if len(watchers) > 0 {
+			if err := uc.taskRepo.AddTaskPeople(ctx, task.ID, tasks.RelationWatcher, watchers); err != nil {
+				return err
+			}
+		}
+		created, err := uc.taskRepo.GetTask(ctx, task.ID)
+		if err != nil {
+			return err
+		}
+		*task = *created
+		return uc.taskRepo.EnqueueEvent(ctx, newTaskEvent(events.TaskCreated, actor, task))
+	})
 	if err != nil {
 		return fmt.Errorf("could not create task in repository: %w", err)
 	}
 
-	notificationMsg := fmt.Sprintf("Task '%s' created for user %d.", task.Title, task.UserID)
-	if err := uc.cache.PublishTaskNotification(ctx, notificationMsg); err != nil {
-		log.Printf("Failed to publish task creation notification: %v", err)
-	}
-
 	return nil
 }
 
-func (uc *taskUsecase) ListTasks(ctx context.Context, userID, status string) ([]tasks.Task, error) {
-	taskList, err := uc.taskRepo.ListTasks(ctx, userID, status)
+func (uc *taskUsecase) GetTask(ctx context.Context, actor tasks.Actor, id int) (*tasks.Task, error) {
+	task, err := uc.getReadableTask(ctx, actor, id)
+	if err != nil {
+		return nil, fmt.Errorf("could not get task: %w", err)
+	}
+	return task, nil
+}
+
+// only tasks the caller created, is assigned to or watches are listed; asking for
+// another user's tasks is forbidden.
+// with team_id, all tasks of that team are listed instead, if the caller is a member.
+// managers and admins see everyone's tasks unless they filter by user_id.
+func (uc *taskUsecase) ListTasks(ctx context.Context, actor tasks.Actor, filter tasks.ListFilter) (*tasks.TaskPage, error) {
+	switch {
+	case actor.CanReadAll():
+	case filter.TeamID != 0:
+		if err := uc.checkTeamMember(ctx, actor, filter.TeamID); err != nil {
+			return nil, fmt.Errorf("could not list tasks of team %d: %w", filter.TeamID, err)
+		}
+	default:
+		for _, userID := range []int{filter.UserID, filter.AssigneeID, filter.WatcherID} {
+			if userID != 0 && userID != actor.UserID {
+				return nil, fmt.Errorf("could not list tasks of user %d: %w", userID, tasks.ErrForbidden)
+			}
+		}
+		filter.VisibleTo = actor.UserID
+	}
+
+	page, err := uc.taskRepo.ListTasks(ctx, filter)
 	if err != nil {
 		return nil, fmt.Errorf("could not list tasks: %w", err)
 	}
-	return taskList, nil
+	return page, nil
 }
 
-func (uc *taskUsecase) UpdateTask(ctx context.Context, task *tasks.Task) (*tasks.Task, error) {
-	updatedTask, err := uc.taskRepo.UpdateTask(ctx, task)
+// replaces the fields PUT covers: title, description, status, priority and due_at.
+// status changes are checked against the transition table in core/tasks before anything is written.
+// like a patch, a replacement that changes nothing writes nothing and keeps the version.
+func (uc *taskUsecase) UpdateTask(ctx context.Context, actor tasks.Actor, task *tasks.Task) (*tasks.Task, error) {
+	var updatedTask *tasks.Task
+	err := uc.taskRepo.WithTx(ctx, func(ctx context.Context) error {
+		// row lock, so the transition is checked against the status we overwrite
+		current, err := uc.taskRepo.GetTaskForUpdate(ctx, task.ID)
+		if err != nil {
+			return err
+		}
+		if err := checkCanEdit(actor, current); err != nil {
+			return err
+		}
+		if err := checkVersion(current, task.Version); err != nil {
+			return err
+		}
+		replacement := *current
+		replacement.Title, replacement.Description = task.Title, task.Description
+		replacement.Status, replacement.Priority, replacement.DueAt = task.Status, task.Priority, task.DueAt
+		if len(diffTasks(current, &replacement)) == 0 {
+			updatedTask = current
+			return nil
+		}
+		if replacement.Status != current.Status {
+			if err := uc.checkStatusChange(ctx, current, replacement.Status); err != nil {
+				return err
+			}
+		}
+
+		updatedTask, err = uc.taskRepo.ReplaceTask(ctx, &replacement)
+		if err != nil {
+			return err
+		}
+		return uc.afterUpdate(ctx, actor, current, updatedTask)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("could not update task: %w", err)
 	}
+	return updatedTask, nil
+}
+
+// the patch is applied under the row lock, so test operations see the state being replaced.
+// a patch that changes nothing writes nothing and keeps the version.
+func (uc *taskUsecase) PatchTask(ctx context.Context, actor tasks.Actor, id int, patch tasks.Patch) (*tasks.Task, error) {
+	var updatedTask *tasks.Task
+	err := uc.taskRepo.WithTx(ctx, func(ctx context.Context) error {
+		current, err := uc.taskRepo.GetTaskForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := checkCanEdit(actor, current); err != nil {
+			return err
+		}
+		if err := checkVersion(current, patch.Version); err != nil {
+			return err
+		}
+		patched, err := patch.Apply(current)
+		if err != nil {
+			return err
+		}
+		if len(diffTasks(current, patched)) == 0 {
+			updatedTask = current
+			return nil
+		}
+		if patched.Status != current.Status {
+			if err := uc.checkStatusChange(ctx, current, patched.Status); err != nil {
+				return err
+			}
+		}
 
-	// to publish the notification message after updating the task succesfully
-	notificationMsg := fmt.Sprintf("Task %d updated. New status: %s.", updatedTask.ID, updatedTask.Status)
-	if err := uc.cache.PublishTaskNotification(ctx, notificationMsg); err != nil {
-		log.Printf("Failed to publish task update notification: %v", err)
+		updatedTask, err = uc.taskRepo.ReplaceTask(ctx, patched)
+		if err != nil {
+			return err
+		}
+		return uc.afterUpdate(ctx, actor, current, updatedTask)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not patch task: %w", err)
 	}
 	return updatedTask, nil
 }
+
+// a non-zero version must be the one the task is at
+func checkVersion(current *tasks.Task, version int) error {
+	if version != 0 && version != current.Version {
+		return fmt.Errorf("task %d is at version %d, not %d: %w", current.ID, current.Version, version, tasks.ErrVersionMismatch)
+	}
+	return nil
+}
+
+// checks moving the locked task current to status
+func (uc *taskUsecase) checkStatusChange(ctx context.Context, current *tasks.Task, status tasks.Status) error {
+	if err := current.Status.ValidateTransition(status); err != nil {
+		return err
+	}
+	if status == tasks.StatusDone && current.Status != tasks.StatusDone && uc.cfg.RequireClosedSubtasks {
+		open, err := uc.taskRepo.CountOpenSubtasks(ctx, current.ID)
+		if err != nil {
+			return err
+		}
+		if open > 0 {
+			return fmt.Errorf("task %d has %d open subtask(s): %w", current.ID, open, tasks.ErrOpenSubtasks)
+		}
+	}
+	return uc.checkPrerequisites(ctx, current, status)
+}
+
+// publishes the update and lets dependents follow a status change
+func (uc *taskUsecase) afterUpdate(ctx context.Context, actor tasks.Actor, current, updatedTask *tasks.Task) error {
+	if err := uc.taskRepo.EnqueueEvent(ctx, newTaskUpdatedEvent(actor, current, updatedTask)); err != nil {
+		return err
+	}
+	// closing or reopening a task blocks or unblocks whatever depends on it
+	if current.Status.IsClosed() != updatedTask.Status.IsClosed() {
+		return uc.syncDependents(ctx, actor, updatedTask.Blocks)
+	}
+	return nil
+}
+
+func (uc *taskUsecase) DeleteTask(ctx context.Context, actor tasks.Actor, id int) error {
+	err := uc.taskRepo.WithTx(ctx, func(ctx context.Context) error {
+		task, err := uc.taskRepo.GetTaskForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !actor.CanDelete(task) {
+			return fmt.Errorf("task %d belongs to another user: %w", task.ID, tasks.ErrForbidden)
+		}
+		if err := uc.taskRepo.DeleteTask(ctx, id); err != nil {
+			return err
+		}
+		if err := uc.taskRepo.EnqueueEvent(ctx, newTaskEvent(events.TaskDeleted, actor, task)); err != nil {
+			return err
+		}
+		// the task's dependencies went with it, which may unblock its dependents
+		return uc.syncDependents(ctx, actor, task.Blocks)
+	})
+	if err != nil {
+		return fmt.Errorf("could not delete task: %w", err)
+	}
+	return nil
+}
+
+// both the task and its new parent must be editable by the caller. moves are
+// serialized, so the cycle check can't be raced.
+func (uc *taskUsecase) SetParent(ctx context.Context, actor tasks.Actor, taskID int, parentID *int) (*tasks.Task, error) {
+	var updatedTask *tasks.Task
+	err := uc.taskRepo.WithTx(ctx, func(ctx context.Context) error {
+		if err := uc.taskRepo.LockTaskHierarchy(ctx); err != nil {
+			return err
+		}
+		current, err := uc.taskRepo.GetTaskForUpdate(ctx, taskID)
+		if err != nil {
+			return err
+		}
+		if err := checkCanEdit(actor, current); err != nil {
+			return err
+		}
+		if parentID != nil {
+			if *parentID == taskID {
+				return fmt.Errorf("task %d: %w", taskID, tasks.ErrTaskCycle)
+			}
+			if _, err := uc.getParent(ctx, actor, *parentID); err != nil {
+				return err
+			}
+			ancestors, err := uc.taskRepo.ListAncestorIDs(ctx, *parentID)
+			if err != nil {
+				return err
+			}
+			if tasks.ContainsID(ancestors, taskID) {
+				return fmt.Errorf("task %d is an ancestor of task %d: %w", taskID, *parentID, tasks.ErrTaskCycle)
+			}
+		}
+
+		if err := uc.taskRepo.SetTaskParent(ctx, taskID, parentID); err != nil {
+			return err
+		}
+		updatedTask, err = uc.taskRepo.GetTask(ctx, taskID)
+		if err != nil {
+			return err
+		}
+		event := newTaskUpdatedEvent(actor, current, updatedTask)
+		if len(event.Changes) == 0 {
+			return nil
+		}
+		return uc.taskRepo.EnqueueEvent(ctx, event)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not set parent: %w", err)
+	}
+	return updatedTask, nil
+}
+
+// whoever can read a task can list all of its direct subtasks
+func (uc *taskUsecase) ListSubtasks(ctx context.Context, actor tasks.Actor, taskID int, filter tasks.ListFilter) (*tasks.TaskPage, error) {
+	if _, err := uc.getReadableTask(ctx, actor, taskID); err != nil {
+		return nil, fmt.Errorf("could not list subtasks: %w", err)
+	}
+	filter.ParentID = taskID
+	page, err := uc.taskRepo.ListTasks(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("could not list subtasks: %w", err)
+	}
+	return page, nil
+}
+
+// loads the task a subtask is being put under; only editable tasks can take subtasks
+func (uc *taskUsecase) getParent(ctx context.Context, actor tasks.Actor, parentID int) (*tasks.Task, error) {
+	parent, err := uc.taskRepo.GetTask(ctx, parentID)
+	if errors.Is(err, tasks.ErrTaskNotFound) {
+		return nil, fmt.Errorf("%w: task %d does not exist", tasks.ErrInvalidParent, parentID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCanEdit(actor, parent); err != nil {
+		return nil, err
+	}
+	return parent, nil
+}
+
+// assignees are managed by whoever may delete the task. anyone who can edit it may add
+// watchers, and anyone who can read it may start watching it themselves.
+func (uc *taskUsecase) AddPeople(ctx context.Context, actor tasks.Actor, taskID int, relation tasks.Relation, userIDs []int) (*tasks.Task, error) {
+	if len(userIDs) == 0 {
+		return nil, fmt.Errorf("%w: no users given", tasks.ErrInvalidUser)
+	}
+	// access is checked before the users are looked up, so the answer can't be
+	// used to find out which user ids exist. checked again below, under the lock.
+	task, err := uc.taskRepo.GetTask(ctx, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("could not add %ss: %w", relation, err)
+	}
+	if err := uc.checkCanManagePeople(ctx, actor, task, relation, userIDs); err != nil {
+		return nil, fmt.Errorf("could not add %ss: %w", relation, err)
+	}
+	if err := uc.validatePeople(ctx, relation, userIDs); err != nil {
+		return nil, fmt.Errorf("could not add %ss: %w", relation, err)
+	}
+
+	var updatedTask *tasks.Task
+	err = uc.taskRepo.WithTx(ctx, func(ctx context.Context) error {
+		current, err := uc.taskRepo.GetTaskForUpdate(ctx, taskID)
+		if err != nil {
+			return err
+		}
+		if err := uc.checkCanManagePeople(ctx, actor, current, relation, userIDs); err != nil {
+			return err
+		}
+		if err := uc.taskRepo.AddTaskPeople(ctx, taskID, relation, userIDs); err != nil {
+			return err
+		}
+		updatedTask, err = uc.taskRepo.GetTask(ctx, taskID)
+		if err != nil {
+			return err
+		}
+		return uc.enqueuePeopleChange(ctx, actor, current, updatedTask)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not add %ss: %w", relation, err)
+	}
+	return updatedTask, nil
+}
+
+// same permissions as AddPeople, so users can always stop watching a task
+func (uc *taskUsecase) RemovePerson(ctx context.Context, actor tasks.Actor, taskID int, relation tasks.Relation, userID int) (*tasks.Task, error) {
+	var updatedTask *tasks.Task
+	err := uc.taskRepo.WithTx(ctx, func(ctx context.Context) error {
+		current, err := uc.taskRepo.GetTaskForUpdate(ctx, taskID)
+		if err != nil {
+			return err
+		}
+		if err := uc.checkCanManagePeople(ctx, actor, current, relation, []int{userID}); err != nil {
+			return err
+		}
+		if err := uc.taskRepo.RemoveTaskPerson(ctx, taskID, relation, userID); err != nil {
+			return err
+		}
+		updatedTask, err = uc.taskRepo.GetTask(ctx, taskID)
+		if err != nil {
+			return err
+		}
+		return uc.enqueuePeopleChange(ctx, actor, current, updatedTask)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not remove %s: %w", relation, err)
+	}
+	return updatedTask, nil
+}
+
+func (uc *taskUsecase) checkCanManagePeople(ctx context.Context, actor tasks.Actor, task *tasks.Task, relation tasks.Relation, userIDs []int) error {
+	if relation == tasks.RelationAssignee {
+		if !actor.CanDelete(task) {
+			return fmt.Errorf("only the owner of task %d can change its assignees: %w", task.ID, tasks.ErrForbidden)
+		}
+		return nil
+	}
+	if actor.CanEdit(task) {
+		return nil
+	}
+	if len(userIDs) == 1 && userIDs[0] == actor.UserID {
+		return uc.checkCanRead(ctx, actor, task)
+	}
+	return fmt.Errorf("task %d belongs to another user: %w", task.ID, tasks.ErrForbidden)
+}
+
+// adding someone already attached or removing someone who isn't changes nothing and isn't published
+func (uc *taskUsecase) enqueuePeopleChange(ctx context.Context, actor tasks.Actor, before, after *tasks.Task) error {
+	event := newTaskUpdatedEvent(actor, before, after)
+	if len(event.Changes) == 0 {
+		return nil
+	}
+	return uc.taskRepo.EnqueueEvent(ctx, event)
+}
+
+// checks the users exist before they are attached to a task.
+// assignees must have verified their email if RequireVerifiedUsers is set.
+func (uc *taskUsecase) validatePeople(ctx context.Context, relation tasks.Relation, userIDs []int) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	if len(userIDs) > tasks.MaxPeoplePerRequest {
+		return fmt.Errorf("%w: at most %d %ss per request", tasks.ErrInvalidUser, tasks.MaxPeoplePerRequest, relation)
+	}
+	ids := make([]int32, len(userIDs))
+	for i, id := range userIDs {
+		ids[i] = int32(id)
+	}
+	res, err := uc.userClient.BatchGetUsers(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("could not look up users: %w", err)
+	}
+	if missing := res.GetMissingIds(); len(missing) > 0 {
+		return fmt.Errorf("%w: no users with ids %v", tasks.ErrInvalidUser, missing)
+	}
+	if relation == tasks.RelationAssignee && uc.cfg.RequireVerifiedUsers {
+		for _, user := range res.GetUsers() {
+			if !user.GetEmailVerified() {
+				return fmt.Errorf("could not assign user %d: %w", user.GetId(), tasks.ErrUnverifiedUser)
+			}
+		}
+	}
+	return nil
+}
+
+// the creator of a task was already checked before it is attached
+func withoutID(ids []int, id int) []int {
+	var out []int
+	for _, v := range ids {
+		if v != id {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// loads a task and checks the actor may see it
+func (uc *taskUsecase) getReadableTask(ctx context.Context, actor tasks.Actor, id int) (*tasks.Task, error) {
+	task, err := uc.taskRepo.GetTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.checkCanRead(ctx, actor, task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+func (uc *taskUsecase) checkCanRead(ctx context.Context, actor tasks.Actor, task *tasks.Task) error {
+	if actor.CanRead(task) {
+		return nil
+	}
+	if task.TeamID == nil {
+		return fmt.Errorf("task %d belongs to another user: %w", task.ID, tasks.ErrForbidden)
+	}
+	if err := uc.checkTeamMember(ctx, actor, *task.TeamID); err != nil {
+		return fmt.Errorf("task %d: %w", task.ID, err)
+	}
+	return nil
+}
+
+func (uc *taskUsecase) checkTeamMember(ctx context.Context, actor tasks.Actor, teamID int) error {
+	res, err := uc.userClient.GetTeamMembership(ctx, int32(teamID), int32(actor.UserID))
+	if err != nil {
+		return fmt.Errorf("could not check team membership: %w", err)
+	}
+	if !res.GetIsMember() {
+		return fmt.Errorf("not a member of team %d: %w", teamID, tasks.ErrForbidden)
+	}
+	return nil
+}
+
+func checkCanEdit(actor tasks.Actor, task *tasks.Task) error {
+	if !actor.CanEdit(task) {
+		return fmt.Errorf("task %d belongs to another user: %w", task.ID, tasks.ErrForbidden)
+	}
+	return nil
+}
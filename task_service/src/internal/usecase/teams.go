@@ -0,0 +1,38 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"task_service/internal/core/tasks"
+)
+
+// team roles as reported by the user service
+const (
+	teamRoleOwner = "owner"
+	teamRoleAdmin = "admin"
+)
+
+// the caller's role in the team, or tasks.ErrForbidden if they aren't a member
+func teamRole(ctx context.Context, client UserServiceClient, actor tasks.Actor, teamID int) (string, error) {
+	res, err := client.GetTeamMembership(ctx, int32(teamID), int32(actor.UserID))
+	if err != nil {
+		return "", fmt.Errorf("could not check team membership: %w", err)
+	}
+	if !res.GetIsMember() {
+		return "", fmt.Errorf("not a member of team %d: %w", teamID, tasks.ErrForbidden)
+	}
+	return res.GetRole(), nil
+}
+
+// ids of the teams the caller belongs to
+func userTeamIDs(ctx context.Context, client UserServiceClient, actor tasks.Actor) ([]int, error) {
+	res, err := client.ListUserTeams(ctx, int32(actor.UserID))
+	if err != nil {
+		return nil, fmt.Errorf("could not list teams: %w", err)
+	}
+	ids := make([]int, 0, len(res.GetTeams()))
+	for _, team := range res.GetTeams() {
+		ids = append(ids, int(team.GetTeamId()))
+	}
+	return ids, nil
+}
@@ -0,0 +1,193 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"task_service/internal/core/tasks"
+	"task_service/internal/core/views"
+)
+
+type viewUsecase struct {
+	viewRepo    ViewRepository
+	taskUsecase TaskUsecase
+	userClient  UserServiceClient
+}
+
+func NewViewUsecase(viewRepo ViewRepository, taskUC TaskUsecase, client UserServiceClient) ViewUsecase {
+	return &viewUsecase{
+		viewRepo:    viewRepo,
+		taskUsecase: taskUC,
+		userClient:  client,
+	}
+}
+
+// Scoping rules follow labels: a personal view is only seen and changed by
+// its owner. A team view is seen by every member of the team and changed by
+// whoever created it or by the team's owners and admins. Running a view goes
+// through TaskUsecase.ListTasks, so it never shows tasks the caller couldn't
+// list by hand.
+
+// with view.TeamID set the view is shared with that team, otherwise it is the caller's
+func (uc *viewUsecase) CreateView(ctx context.Context, actor tasks.Actor, view *views.View) error {
+	if err := view.Normalize(); err != nil {
+		return err
+	}
+	view.OwnerID, view.CreatedBy = nil, actor.UserID
+	if view.TeamID != nil {
+		if _, err := teamRole(ctx, uc.userClient, actor, *view.TeamID); err != nil {
+			return fmt.Errorf("could not create view: %w", err)
+		}
+	} else {
+		view.OwnerID = &actor.UserID
+	}
+	if err := uc.viewRepo.CreateView(ctx, view); err != nil {
+		return fmt.Errorf("could not create view: %w", err)
+	}
+	return nil
+}
+
+func (uc *viewUsecase) GetView(ctx context.Context, actor tasks.Actor, id int) (*views.View, error) {
+	view, err := uc.viewRepo.GetView(ctx, id, actor.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get view: %w", err)
+	}
+	if err := uc.checkCanRead(ctx, actor, view); err != nil {
+		return nil, fmt.Errorf("could not get view: %w", err)
+	}
+	return view, nil
+}
+
+func (uc *viewUsecase) GetDefaultView(ctx context.Context, actor tasks.Actor) (*views.View, error) {
+	id, err := uc.viewRepo.GetDefaultViewID(ctx, actor.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get default view: %w", err)
+	}
+	return uc.GetView(ctx, actor, id)
+}
+
+func (uc *viewUsecase) ListViews(ctx context.Context, actor tasks.Actor) ([]views.View, error) {
+	teamIDs, err := userTeamIDs(ctx, uc.userClient, actor)
+	if err != nil {
+		return nil, fmt.Errorf("could not list views: %w", err)
+	}
+	viewList, err := uc.viewRepo.ListViews(ctx, actor.UserID, teamIDs)
+	if err != nil {
+		return nil, fmt.Errorf("could not list views: %w", err)
+	}
+	return viewList, nil
+}
+
+// replaces the name, filter, sort and columns; the scope of a view can't change
+func (uc *viewUsecase) UpdateView(ctx context.Context, actor tasks.Actor, view *views.View) (*views.View, error) {
+	current, err := uc.viewRepo.GetView(ctx, view.ID, actor.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("could not update view: %w", err)
+	}
+	if err := uc.checkCanManage(ctx, actor, current); err != nil {
+		return nil, fmt.Errorf("could not update view: %w", err)
+	}
+	if err := view.Normalize(); err != nil {
+		return nil, err
+	}
+	if err := uc.viewRepo.UpdateView(ctx, view, actor.UserID); err != nil {
+		return nil, fmt.Errorf("could not update view: %w", err)
+	}
+	return view, nil
+}
+
+func (uc *viewUsecase) DeleteView(ctx context.Context, actor tasks.Actor, id int) error {
+	view, err := uc.viewRepo.GetView(ctx, id, actor.UserID)
+	if err != nil {
+		return fmt.Errorf("could not delete view: %w", err)
+	}
+	if err := uc.checkCanManage(ctx, actor, view); err != nil {
+		return fmt.Errorf("could not delete view: %w", err)
+	}
+	if err := uc.viewRepo.DeleteView(ctx, id); err != nil {
+		return fmt.Errorf("could not delete view: %w", err)
+	}
+	return nil
+}
+
+// any view the caller can see can be their default, replacing the previous one
+func (uc *viewUsecase) SetDefaultView(ctx context.Context, actor tasks.Actor, id int) (*views.View, error) {
+	view, err := uc.GetView(ctx, actor, id)
+	if err != nil {
+		return nil, fmt.Errorf("could not set default view: %w", err)
+	}
+	if err := uc.viewRepo.SetDefaultView(ctx, actor.UserID, id); err != nil {
+		return nil, fmt.Errorf("could not set default view: %w", err)
+	}
+	view.IsDefault = true
+	return view, nil
+}
+
+func (uc *viewUsecase) ClearDefaultView(ctx context.Context, actor tasks.Actor) error {
+	if err := uc.viewRepo.ClearDefaultView(ctx, actor.UserID); err != nil {
+		return fmt.Errorf("could not clear default view: %w", err)
+	}
+	return nil
+}
+
+func (uc *viewUsecase) ListViewTasks(ctx context.Context, actor tasks.Actor, id int, limit int, cursor string) (*views.TaskPage, error) {
+	view, err := uc.GetView(ctx, actor, id)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := view.ListFilter(actor.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("could not run view %d: %w", id, err)
+	}
+	if limit > 0 {
+		filter.Limit = limit
+	}
+	if cursor != "" {
+		c, err := tasks.DecodeCursor(cursor, filter.Sort)
+		if err != nil {
+			return nil, err
+		}
+		filter.Cursor = c
+	}
+	page, err := uc.taskUsecase.ListTasks(ctx, actor, filter)
+	if err != nil {
+		return nil, err
+	}
+	return view.Project(page)
+}
+
+func (uc *viewUsecase) checkCanRead(ctx context.Context, actor tasks.Actor, view *views.View) error {
+	if actor.CanReadAll() {
+		return nil
+	}
+	if view.TeamID == nil {
+		if *view.OwnerID != actor.UserID {
+			// other users' views are invisible, not forbidden
+			return fmt.Errorf("view %d belongs to another user: %w", view.ID, views.ErrViewNotFound)
+		}
+		return nil
+	}
+	if _, err := teamRole(ctx, uc.userClient, actor, *view.TeamID); err != nil {
+		return fmt.Errorf("view %d: %w", view.ID, views.ErrViewNotFound)
+	}
+	return nil
+}
+
+func (uc *viewUsecase) checkCanManage(ctx context.Context, actor tasks.Actor, view *views.View) error {
+	if actor.CanEditAll() {
+		return nil
+	}
+	if view.TeamID == nil {
+		if *view.OwnerID != actor.UserID {
+			return fmt.Errorf("view %d belongs to another user: %w", view.ID, views.ErrViewNotFound)
+		}
+		return nil
+	}
+	role, err := teamRole(ctx, uc.userClient, actor, *view.TeamID)
+	if err != nil {
+		return fmt.Errorf("view %d: %w", view.ID, views.ErrViewNotFound)
+	}
+	if view.CreatedBy != actor.UserID && role != teamRoleOwner && role != teamRoleAdmin {
+		return fmt.Errorf("only its creator and team owners and admins can change view %d: %w", view.ID, tasks.ErrForbidden)
+	}
+	return nil
+}
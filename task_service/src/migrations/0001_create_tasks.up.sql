@@ -1,3 +1,4 @@
+-- IF NOT EXISTS because databases created before versioned migrations already have the table
 CREATE TABLE IF NOT EXISTS tasks (
     id SERIAL PRIMARY KEY, -- id of the task
     title VARCHAR(255) NOT NULL,
@@ -6,4 +7,4 @@ CREATE TABLE IF NOT EXISTS tasks (
     user_id INT, --  id of the user this task is assigned to
     created_at TIMESTAMPTZ NOT NULL DEFAULT (now()),
     updated_at TIMESTAMPTZ NOT NULL DEFAULT (now())
-);
\ No newline at end of file
+);
@@ -0,0 +1,15 @@
+-- tidy up free-form statuses written before the status check existed
+UPDATE tasks SET status = CASE
+        WHEN lower(trim(status)) IN ('completed', 'complete', 'finished') THEN 'done'
+        WHEN lower(trim(status)) IN ('in_progres', 'in progress', 'in-progress') THEN 'in_progress'
+        WHEN lower(trim(status)) IN ('canceled') THEN 'cancelled'
+        ELSE lower(trim(status))
+    END
+WHERE status NOT IN ('pending', 'in_progress', 'blocked', 'in_review', 'done', 'cancelled');
+
+UPDATE tasks SET status = 'pending'
+WHERE status NOT IN ('pending', 'in_progress', 'blocked', 'in_review', 'done', 'cancelled');
+
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_status_check;
+ALTER TABLE tasks ADD CONSTRAINT tasks_status_check
+    CHECK (status IN ('pending', 'in_progress', 'blocked', 'in_review', 'done', 'cancelled'));
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_tasks_user_id_created_at_id;
+DROP INDEX IF EXISTS idx_tasks_status_id;
+DROP INDEX IF EXISTS idx_tasks_title_id;
+DROP INDEX IF EXISTS idx_tasks_updated_at_id;
+DROP INDEX IF EXISTS idx_tasks_created_at_id;
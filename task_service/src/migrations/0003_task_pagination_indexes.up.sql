@@ -0,0 +1,6 @@
+-- keyset pagination indexes, one per sortable column with id as tie-breaker
+CREATE INDEX IF NOT EXISTS idx_tasks_created_at_id ON tasks (created_at, id);
+CREATE INDEX IF NOT EXISTS idx_tasks_updated_at_id ON tasks (updated_at, id);
+CREATE INDEX IF NOT EXISTS idx_tasks_title_id ON tasks (title, id);
+CREATE INDEX IF NOT EXISTS idx_tasks_status_id ON tasks (status, id);
+CREATE INDEX IF NOT EXISTS idx_tasks_user_id_created_at_id ON tasks (user_id, created_at, id);
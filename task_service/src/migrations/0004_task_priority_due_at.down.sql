@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_tasks_user_id_due_at;
+DROP INDEX IF EXISTS idx_tasks_user_id_priority;
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_priority_check;
+ALTER TABLE tasks DROP COLUMN IF EXISTS due_at;
+ALTER TABLE tasks DROP COLUMN IF EXISTS priority;
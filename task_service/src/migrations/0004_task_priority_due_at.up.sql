@@ -0,0 +1,9 @@
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS priority VARCHAR(20) NOT NULL DEFAULT 'medium';
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS due_at TIMESTAMPTZ;
+
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_priority_check;
+ALTER TABLE tasks ADD CONSTRAINT tasks_priority_check
+    CHECK (priority IN ('low', 'medium', 'high', 'urgent'));
+
+CREATE INDEX IF NOT EXISTS idx_tasks_user_id_priority ON tasks (user_id, priority);
+CREATE INDEX IF NOT EXISTS idx_tasks_user_id_due_at ON tasks (user_id, due_at) WHERE due_at IS NOT NULL;
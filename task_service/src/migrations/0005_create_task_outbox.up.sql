@@ -0,0 +1,14 @@
+-- events written in the same transaction as the task change, drained to redis by the outbox relay
+CREATE TABLE IF NOT EXISTS task_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    event_id UUID NOT NULL UNIQUE,
+    task_id INT NOT NULL, -- no foreign key: task.deleted events outlive their task
+    event_type VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT (now()),
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_outbox_task_id_id ON task_outbox (task_id, id);
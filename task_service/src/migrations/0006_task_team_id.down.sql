@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_tasks_team_id_created_at;
+ALTER TABLE tasks DROP COLUMN IF EXISTS team_id;
@@ -0,0 +1,4 @@
+-- team the task is shared with; NULL for personal tasks. teams live in the user service.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS team_id INT;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_team_id_created_at ON tasks (team_id, created_at) WHERE team_id IS NOT NULL;
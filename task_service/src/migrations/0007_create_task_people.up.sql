@@ -0,0 +1,15 @@
+-- people attached to a task besides its creator (tasks.user_id)
+CREATE TABLE IF NOT EXISTS task_people (
+    task_id INT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    user_id INT NOT NULL,
+    relation VARCHAR(16) NOT NULL CHECK (relation IN ('assignee', 'watcher')),
+    added_at TIMESTAMPTZ NOT NULL DEFAULT (now()),
+    PRIMARY KEY (task_id, relation, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_people_user_id ON task_people (user_id, relation);
+
+-- tasks.user_id used to mean "assigned to"; keep those users assigned
+INSERT INTO task_people (task_id, user_id, relation)
+SELECT id, user_id, 'assignee' FROM tasks WHERE user_id IS NOT NULL
+ON CONFLICT DO NOTHING;
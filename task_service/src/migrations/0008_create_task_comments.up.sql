@@ -0,0 +1,13 @@
+-- comments are soft deleted: deleted_at is set and the row is hidden from the api
+CREATE TABLE IF NOT EXISTS task_comments (
+    id SERIAL PRIMARY KEY,
+    task_id INT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    author_id INT NOT NULL,
+    body TEXT NOT NULL,
+    mentions INT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT (now()),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT (now()),
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_comments_task_id_id ON task_comments (task_id, id) WHERE deleted_at IS NULL;
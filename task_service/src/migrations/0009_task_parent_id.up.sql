@@ -0,0 +1,15 @@
+-- subtasks point at their parent. deleting a parent turns its children into top-level tasks.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS parent_id INT REFERENCES tasks (id) ON DELETE SET NULL;
+-- postgres has no ADD CONSTRAINT IF NOT EXISTS
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint
+        WHERE conname = 'tasks_parent_id_not_self' AND conrelid = 'tasks'::regclass
+    ) THEN
+        ALTER TABLE tasks ADD CONSTRAINT tasks_parent_id_not_self CHECK (parent_id <> id);
+    END IF;
+END
+$$;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_parent_id ON tasks (parent_id) WHERE parent_id IS NOT NULL;
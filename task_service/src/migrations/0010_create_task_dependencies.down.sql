@@ -0,0 +1,2 @@
+ALTER TABLE tasks DROP COLUMN IF EXISTS blocked_from;
+DROP TABLE IF EXISTS task_dependencies;
@@ -0,0 +1,14 @@
+-- task_id can't be worked on until depends_on_id is done or cancelled
+CREATE TABLE IF NOT EXISTS task_dependencies (
+    task_id INT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    depends_on_id INT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT (now()),
+    PRIMARY KEY (task_id, depends_on_id),
+    CHECK (task_id <> depends_on_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_dependencies_depends_on_id ON task_dependencies (depends_on_id);
+
+-- status a task was in before it was blocked by an open prerequisite; NULL unless
+-- the block was automatic. it is restored once the last prerequisite closes.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS blocked_from VARCHAR(20);
@@ -0,0 +1,21 @@
+-- labels belong to a user or to a team (teams live in the user service)
+CREATE TABLE IF NOT EXISTS labels (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(50) NOT NULL,
+    color CHAR(7) NOT NULL,
+    owner_id INT,
+    team_id INT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT (now()),
+    CHECK ((owner_id IS NULL) <> (team_id IS NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_labels_owner_id_name ON labels (owner_id, lower(name)) WHERE owner_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_labels_team_id_name ON labels (team_id, lower(name)) WHERE team_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS task_labels (
+    task_id INT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    label_id INT NOT NULL REFERENCES labels (id) ON DELETE CASCADE,
+    PRIMARY KEY (task_id, label_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_labels_label_id ON task_labels (label_id);
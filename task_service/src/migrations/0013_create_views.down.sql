@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS default_views;
+DROP TABLE IF EXISTS views;
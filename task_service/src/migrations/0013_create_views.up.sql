@@ -0,0 +1,25 @@
+-- saved task lists. like labels, a view belongs to a user or to a team
+CREATE TABLE IF NOT EXISTS views (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    filter TEXT NOT NULL DEFAULT '',
+    sort VARCHAR(50) NOT NULL DEFAULT '',
+    columns TEXT[] NOT NULL DEFAULT '{}',
+    owner_id INT,
+    team_id INT,
+    created_by INT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT (now()),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT (now()),
+    CHECK ((owner_id IS NULL) <> (team_id IS NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_views_owner_id_name ON views (owner_id, lower(name)) WHERE owner_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_views_team_id_name ON views (team_id, lower(name)) WHERE team_id IS NOT NULL;
+
+-- at most one default view per user
+CREATE TABLE IF NOT EXISTS default_views (
+    user_id INT PRIMARY KEY,
+    view_id INT NOT NULL REFERENCES views (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_default_views_view_id ON default_views (view_id);
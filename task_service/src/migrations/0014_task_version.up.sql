@@ -0,0 +1,2 @@
+-- bumped on every write to the task row; exposed as the task's ETag
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
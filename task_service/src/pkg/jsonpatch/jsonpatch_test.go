@@ -0,0 +1,215 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// reports whether two JSON documents hold the same value
+func sameJSON(t *testing.T, a, b []byte) bool {
+	t.Helper()
+	var x, y interface{}
+	if err := json.Unmarshal(a, &x); err != nil {
+		t.Fatalf("invalid JSON %s: %v", a, err)
+	}
+	if err := json.Unmarshal(b, &y); err != nil {
+		t.Fatalf("invalid JSON %s: %v", b, err)
+	}
+	return reflect.DeepEqual(x, y)
+}
+
+// the examples of RFC 7386, appendix A
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		doc, patch, want string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, tt := range tests {
+		got, err := MergePatch([]byte(tt.doc), []byte(tt.patch))
+		if err != nil {
+			t.Errorf("MergePatch(%s, %s): %v", tt.doc, tt.patch, err)
+			continue
+		}
+		if !sameJSON(t, got, []byte(tt.want)) {
+			t.Errorf("MergePatch(%s, %s) = %s, want %s", tt.doc, tt.patch, got, tt.want)
+		}
+	}
+
+	// numbers come back as written, not rounded through float64
+	const big = `{"n":12345678901234567891}`
+	if got, err := MergePatch([]byte(big), []byte(`{}`)); err != nil || string(got) != big {
+		t.Errorf("MergePatch(%s, {}) = %s, %v", big, got, err)
+	}
+
+	if _, err := MergePatch([]byte(`{}`), []byte(`{"a":`)); !errors.Is(err, ErrInvalidPatch) {
+		t.Errorf("MergePatch with malformed JSON: error = %v, want ErrInvalidPatch", err)
+	}
+}
+
+// the examples of RFC 6902, appendix A, followed by a few of our own
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name, doc, patch, want string
+		err                    error // want is ignored when set
+	}{
+		{"A.1 adding an object member",
+			`{"foo":"bar"}`,
+			`[{"op":"add","path":"/baz","value":"qux"}]`,
+			`{"baz":"qux","foo":"bar"}`, nil},
+		{"A.2 adding an array element",
+			`{"foo":["bar","baz"]}`,
+			`[{"op":"add","path":"/foo/1","value":"qux"}]`,
+			`{"foo":["bar","qux","baz"]}`, nil},
+		{"A.3 removing an object member",
+			`{"baz":"qux","foo":"bar"}`,
+			`[{"op":"remove","path":"/baz"}]`,
+			`{"foo":"bar"}`, nil},
+		{"A.4 removing an array element",
+			`{"foo":["bar","qux","baz"]}`,
+			`[{"op":"remove","path":"/foo/1"}]`,
+			`{"foo":["bar","baz"]}`, nil},
+		{"A.5 replacing a value",
+			`{"baz":"qux","foo":"bar"}`,
+			`[{"op":"replace","path":"/baz","value":"boo"}]`,
+			`{"baz":"boo","foo":"bar"}`, nil},
+		{"A.6 moving a value",
+			`{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+			`[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			`{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`, nil},
+		{"A.7 moving an array element",
+			`{"foo":["all","grass","cows","eat"]}`,
+			`[{"op":"move","from":"/foo/1","path":"/foo/3"}]`,
+			`{"foo":["all","cows","eat","grass"]}`, nil},
+		{"A.8 testing a value: success",
+			`{"baz":"qux","foo":["a",2,"c"]}`,
+			`[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`,
+			`{"baz":"qux","foo":["a",2,"c"]}`, nil},
+		{"A.9 testing a value: error",
+			`{"baz":"qux"}`,
+			`[{"op":"test","path":"/baz","value":"bar"}]`,
+			``, ErrConflict},
+		{"A.10 adding a nested member object",
+			`{"foo":"bar"}`,
+			`[{"op":"add","path":"/child","value":{"grandchild":{}}}]`,
+			`{"foo":"bar","child":{"grandchild":{}}}`, nil},
+		{"A.11 ignoring unrecognized elements",
+			`{"foo":"bar"}`,
+			`[{"op":"add","path":"/baz","value":"qux","xyz":123}]`,
+			`{"foo":"bar","baz":"qux"}`, nil},
+		{"A.12 adding to a nonexistent target",
+			`{"foo":"bar"}`,
+			`[{"op":"add","path":"/baz/bat","value":"qux"}]`,
+			``, ErrConflict},
+		{"A.14 ~ escape ordering",
+			`{"/":9,"~1":10}`,
+			`[{"op":"test","path":"/~01","value":10}]`,
+			`{"/":9,"~1":10}`, nil},
+		{"A.15 comparing strings and numbers",
+			`{"/":9,"~1":10}`,
+			`[{"op":"test","path":"/~01","value":"10"}]`,
+			``, ErrConflict},
+		{"A.16 adding an array value",
+			`{"foo":["bar"]}`,
+			`[{"op":"add","path":"/foo/-","value":["abc","def"]}]`,
+			`{"foo":["bar",["abc","def"]]}`, nil},
+
+		{"null is a value",
+			`{"foo":"bar"}`,
+			`[{"op":"replace","path":"/foo","value":null}]`,
+			`{"foo":null}`, nil},
+		{"numbers are tested by value",
+			`{"n":1}`,
+			`[{"op":"test","path":"/n","value":1.0}]`,
+			`{"n":1}`, nil},
+		{"copy",
+			`{"a":{"b":[1]}}`,
+			`[{"op":"copy","from":"/a","path":"/c"},{"op":"add","path":"/c/b/-","value":2}]`,
+			`{"a":{"b":[1]},"c":{"b":[1,2]}}`, nil},
+		{"replacing the whole document",
+			`{"a":1}`,
+			`[{"op":"replace","path":"","value":[1]}]`,
+			`[1]`, nil},
+		{"operations apply in order",
+			`{}`,
+			`[{"op":"add","path":"/a","value":1},{"op":"test","path":"/a","value":1},{"op":"remove","path":"/a"}]`,
+			`{}`, nil},
+		{"a failed operation undoes the others",
+			`{"a":1}`,
+			`[{"op":"remove","path":"/a"},{"op":"test","path":"/a","value":1}]`,
+			``, ErrConflict},
+		{"missing value",
+			`{}`,
+			`[{"op":"add","path":"/a"}]`,
+			``, ErrInvalidPatch},
+		{"missing path",
+			`{}`,
+			`[{"op":"remove"}]`,
+			``, ErrInvalidPatch},
+		{"missing from",
+			`{"a":1}`,
+			`[{"op":"move","path":"/b"}]`,
+			``, ErrInvalidPatch},
+		{"unknown op",
+			`{}`,
+			`[{"op":"merge","path":"/a","value":1}]`,
+			``, ErrInvalidPatch},
+		{"path without a leading slash",
+			`{"a":1}`,
+			`[{"op":"remove","path":"a"}]`,
+			``, ErrInvalidPatch},
+		{"index with a leading zero",
+			`{"a":[1,2]}`,
+			`[{"op":"remove","path":"/a/01"}]`,
+			``, ErrInvalidPatch},
+		{"index out of bounds",
+			`{"a":[1,2]}`,
+			`[{"op":"replace","path":"/a/2","value":3}]`,
+			``, ErrConflict},
+		{"replacing a missing member",
+			`{}`,
+			`[{"op":"replace","path":"/a","value":1}]`,
+			``, ErrConflict},
+		{"moving a value into itself",
+			`{"a":{"b":1}}`,
+			`[{"op":"move","from":"/a","path":"/a/c"}]`,
+			``, ErrInvalidPatch},
+		{"not an array",
+			`{}`,
+			`{"op":"add","path":"/a","value":1}`,
+			``, ErrInvalidPatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Apply([]byte(tt.doc), []byte(tt.patch))
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("Apply error = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Apply: %v", err)
+			}
+			if !sameJSON(t, got, []byte(tt.want)) {
+				t.Errorf("Apply = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
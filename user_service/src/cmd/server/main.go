@@ -4,8 +4,11 @@ import (
 	"log"
 	"net"
 	"net/http"
+	"os"
 	"user_service/internal/adaptors/persistance"
+	"user_service/internal/adaptors/redis"
 	"user_service/internal/config"
+	"user_service/internal/core/users"
 	"user_service/internal/interfaces/input/api/rest/handler"
 	"user_service/internal/interfaces/input/api/rest/middleware"
 	grpcServer "user_service/internal/interfaces/output/grpc/server"
@@ -23,28 +26,83 @@ func main() {
 		log.Fatalf("could not load config: %v", err)
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrateCommand(cfg, os.Args[2:]); err != nil {
+			log.Fatalf("migrate: %v", err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "set-role" {
+		if err := runSetRoleCommand(cfg, os.Args[2:]); err != nil {
+			log.Fatalf("set-role: %v", err)
+		}
+		return
+	}
+
 	dbStore, err := persistance.NewDBStore(cfg.DBSource)
 	if err != nil {
 		log.Fatalf("could not connect to database: %v", err)
 	}
 
-	userUsecase := usecase.NewUserUsecase(dbStore, cfg.JWTSecretKey)
+	emailPublisher, err := redis.NewEmailPublisher(cfg.RedisAddress)
+	if err != nil {
+		log.Fatalf("could not connect to redis: %v", err)
+	}
+
+	verificationUsecase := usecase.NewEmailVerificationUsecase(dbStore, emailPublisher, cfg.JWTSecretKey, cfg.EmailVerificationURL, cfg.EmailVerificationTTL, cfg.EmailVerificationCooldown)
+	userUsecase := usecase.NewUserUsecase(dbStore, dbStore, verificationUsecase, usecase.UserUsecaseConfig{
+		JWTSecretKey:         cfg.JWTSecretKey,
+		AccessTokenTTL:       cfg.AccessTokenTTL,
+		RefreshTokenTTL:      cfg.RefreshTokenTTL,
+		RequireVerifiedEmail: cfg.RequireVerifiedEmail,
+	})
+	teamUsecase := usecase.NewTeamUsecase(dbStore, dbStore, emailPublisher, cfg.TeamInviteURL, cfg.TeamInviteTTL)
+	passwordResetUsecase := usecase.NewPasswordResetUsecase(dbStore, dbStore, emailPublisher, cfg.PasswordResetURL, cfg.PasswordResetTTL, cfg.PasswordResetCooldown)
 
 	// start grpc server in another goroutine
-	go startGRPCServer(userUsecase, cfg.GRPCServerAddress)
+	go startGRPCServer(userUsecase, teamUsecase, cfg.GRPCServerAddress)
 
 	// REST server start
 	userHandler := handler.NewUserHandler(userUsecase)
+	passwordHandler := handler.NewPasswordHandler(passwordResetUsecase)
+	verificationHandler := handler.NewVerificationHandler(verificationUsecase)
+	teamHandler := handler.NewTeamHandler(teamUsecase)
 	r := chi.NewRouter()
 	r.Use(chiMiddleware.Logger)
 	r.Use(chiMiddleware.Recoverer)
 
 	r.Post("/register", userHandler.RegisterUser)
 	r.Post("/login", userHandler.Login)
+	r.Post("/token/refresh", userHandler.RefreshToken)
+	r.Post("/password/forgot", passwordHandler.ForgotPassword)
+	r.Post("/password/reset", passwordHandler.ResetPassword)
+	r.Get("/verify-email", verificationHandler.VerifyEmail)
+	r.Post("/verify-email/resend", verificationHandler.ResendVerification)
 
 	r.Group(func(r chi.Router) {
-		r.Use(middleware.AuthMiddleware(cfg.JWTSecretKey))
+		r.Use(middleware.AuthMiddleware(cfg.JWTSecretKey, userUsecase))
 		r.Get("/profile", userHandler.GetProfile)
+		r.Post("/logout", userHandler.Logout)
+
+		r.Route("/teams", func(r chi.Router) {
+			r.Post("/", teamHandler.CreateTeam)
+			r.Get("/", teamHandler.ListTeams)
+			r.Post("/invites/accept", teamHandler.AcceptInvite)
+			r.Get("/{id}", teamHandler.GetTeam)
+			r.Post("/{id}/invites", teamHandler.InviteMember)
+			r.Put("/{id}/members/{user_id}", teamHandler.ChangeMemberRole)
+			r.Delete("/{id}/members/{user_id}", teamHandler.RemoveMember)
+		})
+
+		r.Route("/admin/users", func(r chi.Router) {
+			r.With(middleware.RequirePermission(users.PermListUsers)).Get("/", userHandler.ListUsers)
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.RequirePermission(users.PermManageUsers))
+				r.Put("/{id}/role", userHandler.ChangeRole)
+				r.Post("/{id}/disable", userHandler.DisableUser)
+				r.Post("/{id}/enable", userHandler.EnableUser)
+			})
+		})
 	})
 
 	log.Printf("REST Service starting on %s", cfg.ServerAddress)
@@ -53,14 +111,14 @@ func main() {
 	}
 }
 
-func startGRPCServer(userUsecase usecase.UserUsecase, address string) {
+func startGRPCServer(userUsecase usecase.UserUsecase, teamUsecase usecase.TeamUsecase, address string) {
 	lis, err := net.Listen("tcp", address)
 	if err != nil {
 		log.Fatalf("failed to listen for gRPC: %v", err)
 	}
 
 	s := grpc.NewServer()
-	userServer := grpcServer.NewUserServer(userUsecase)
+	userServer := grpcServer.NewUserServer(userUsecase, teamUsecase)
 	pb.RegisterUserServiceServer(s, userServer)
 
 	log.Printf("gRPC server listening at %v", lis.Addr())
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"shared/migrate"
+	"user_service/internal/adaptors/persistance"
+	"user_service/internal/config"
+)
+
+// handles "user-service migrate ...". the server itself is not started.
+func runMigrateCommand(cfg config.Config, args []string) error {
+	db, err := persistance.OpenDB(cfg.DBSource)
+	if err != nil {
+		return fmt.Errorf("could not connect to database: %w", err)
+	}
+	defer db.Close()
+
+	migrator, err := persistance.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+	return migrate.RunCommand(context.Background(), migrator, "user-service", args, os.Stdout)
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"user_service/internal/adaptors/persistance"
+	"user_service/internal/config"
+	"user_service/internal/core/users"
+)
+
+const setRoleUsage = "usage: user-service set-role <email> <user|manager|admin>"
+
+// handles "user-service set-role ...". this is how the first admin is created,
+// since roles can otherwise only be changed by an admin.
+func runSetRoleCommand(cfg config.Config, args []string) error {
+	if len(args) != 2 {
+		return errors.New(setRoleUsage)
+	}
+	role, err := users.ParseRole(args[1])
+	if err != nil {
+		return err
+	}
+
+	db, err := persistance.OpenDB(cfg.DBSource)
+	if err != nil {
+		return fmt.Errorf("could not connect to database: %w", err)
+	}
+	defer db.Close()
+	store := &persistance.DBStore{DB: db}
+
+	user, err := store.GetUserByEmail(args[0])
+	if err != nil {
+		return err
+	}
+	if _, err := store.UpdateUserRole(user.ID, role); err != nil {
+		return err
+	}
+	// so the old role doesn't linger in tokens already handed out
+	if err := store.RevokeUserSessions(user.ID, "role changed"); err != nil {
+		return err
+	}
+	fmt.Printf("user %d (%s) is now %s\n", user.ID, user.Email, role)
+	return nil
+}
@@ -1,13 +1,17 @@
 package persistance
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	"io/ioutil"
 	"log"
+	"shared/migrate"
+	"time"
 	"user_service/internal/core/users"
+	"user_service/migrations"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 type DBStore struct {
@@ -15,6 +19,18 @@ type DBStore struct {
 }
 
 func NewDBStore(dbSource string) (*DBStore, error) {
+	db, err := OpenDB(dbSource)
+	if err != nil {
+		return nil, err
+	}
+	if err := runMigrations(db); err != nil {
+		return nil, fmt.Errorf("could not run migrations: %w", err)
+	}
+	return &DBStore{DB: db}, nil
+}
+
+// opens and pings the database without touching the schema
+func OpenDB(dbSource string) (*sql.DB, error) {
 	db, err := sql.Open("postgres", dbSource)
 	if err != nil {
 		return nil, fmt.Errorf("could not open database: %w", err)
@@ -23,42 +39,64 @@ func NewDBStore(dbSource string) (*DBStore, error) {
 		return nil, fmt.Errorf("could not ping database: %w", err)
 	}
 	log.Println("Database connection successful.")
-	if err := runMigrations(db); err != nil {
-		return nil, fmt.Errorf("could not run migrations: %w", err)
-	}
-	return &DBStore{DB: db}, nil
+	return db, nil
 }
 
-// to execute sql file.
+// migrator over the migrations embedded in the binary
+func NewMigrator(db *sql.DB) (*migrate.Migrator, error) {
+	return migrate.New(db, migrations.FS)
+}
+
+// applies any pending migrations. safe to call from several replicas at once.
 func runMigrations(db *sql.DB) error {
-	migration, err := ioutil.ReadFile("migrations/init.sql")
+	migrator, err := NewMigrator(db)
 	if err != nil {
-		return fmt.Errorf("could not read migration file: %w", err)
+		return err
 	}
-	_, err = db.Exec(string(migration))
+	applied, err := migrator.Up(context.Background())
 	if err != nil {
-		return fmt.Errorf("could not execute migration: %w", err)
+		return err
 	}
-	log.Println("Database migration successful.")
+	log.Printf("Database migration successful, %d migration(s) applied.", applied)
 	return nil
 }
 
 func (store *DBStore) CreateUser(user *users.User) error {
-	query := `INSERT INTO users (username, email, password_hash) VALUES ($1, $2, $3) RETURNING id`
-	err := store.DB.QueryRow(query, user.Username, user.Email, user.PasswordHash).Scan(&user.ID)
+	if user.Role == "" {
+		user.Role = users.DefaultRole
+	}
+	query := `INSERT INTO users (username, email, password_hash, role) VALUES ($1, $2, $3, $4) RETURNING id`
+	err := store.DB.QueryRow(query, user.Username, user.Email, user.PasswordHash, user.Role).Scan(&user.ID)
 	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == "idx_users_username_lower" {
+			return users.ErrUsernameTaken
+		}
 		return fmt.Errorf("could not create user: %w", err)
 	}
 	return nil
 }
 
-func (store *DBStore) GetUserByEmail(email string) (*users.User, error) {
+const userColumns = "id, username, email, email_verified, role, disabled_at, password_hash"
+
+func scanUser(row *sql.Row) (*users.User, error) {
 	user := &users.User{}
-	query := `SELECT id, username, email, password_hash FROM users WHERE email = $1`
-	err := store.DB.QueryRow(query, email).Scan(&user.ID, &user.Username, &user.Email, &user.PasswordHash)
+	var disabledAt sql.NullTime
+	if err := row.Scan(&user.ID, &user.Username, &user.Email, &user.EmailVerified, &user.Role, &disabledAt, &user.PasswordHash); err != nil {
+		return nil, err
+	}
+	if disabledAt.Valid {
+		user.DisabledAt = &disabledAt.Time
+	}
+	return user, nil
+}
+
+func (store *DBStore) GetUserByEmail(email string) (*users.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE email = $1`
+	user, err := scanUser(store.DB.QueryRow(query, email))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("user not found")
+			return nil, users.ErrUserNotFound
 		}
 		return nil, fmt.Errorf("could not get user by email: %w", err)
 	}
@@ -66,14 +104,140 @@ func (store *DBStore) GetUserByEmail(email string) (*users.User, error) {
 }
 
 func (store *DBStore) GetUserByID(id int) (*users.User, error) {
-	user := &users.User{}
-	query := `SELECT id, username, email FROM users WHERE id = $1`
-	err := store.DB.QueryRow(query, id).Scan(&user.ID, &user.Username, &user.Email)
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
+	user, err := scanUser(store.DB.QueryRow(query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("user not found")
+			return nil, users.ErrUserNotFound
 		}
 		return nil, fmt.Errorf("could not get user by id: %w", err)
 	}
 	return user, nil
 }
+
+// fetches all users whose id is in ids. unknown ids are simply absent from the result.
+func (store *DBStore) GetUsersByIDs(ids []int) ([]*users.User, error) {
+	query := `SELECT id, username, email, email_verified, role, disabled_at FROM users WHERE id = ANY($1) ORDER BY id`
+	return store.queryUsers(query, pq.Array(ids))
+}
+
+// matches usernames case-insensitively; the usernames must already be lower case.
+// usernames are unique ignoring case since migration 0007.
+func (store *DBStore) GetActiveUsersByUsernames(usernames []string) ([]*users.User, error) {
+	query := `SELECT id, username, email, email_verified, role, disabled_at FROM users
+		WHERE lower(username) = ANY($1) AND disabled_at IS NULL ORDER BY id`
+	return store.queryUsers(query, pq.Array(usernames))
+}
+
+// one page of users in id order, starting after afterID
+func (store *DBStore) ListUsers(afterID int, limit int) ([]*users.User, error) {
+	query := `SELECT id, username, email, email_verified, role, disabled_at FROM users WHERE id > $1 ORDER BY id LIMIT $2`
+	return store.queryUsers(query, afterID, limit)
+}
+
+func (store *DBStore) queryUsers(query string, args ...interface{}) ([]*users.User, error) {
+	rows, err := store.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("could not query users: %w", err)
+	}
+	defer rows.Close()
+	var userList []*users.User
+	for rows.Next() {
+		user := &users.User{}
+		var disabledAt sql.NullTime
+		if err := rows.Scan(&user.ID, &user.Username, &user.Email, &user.EmailVerified, &user.Role, &disabledAt); err != nil {
+			return nil, fmt.Errorf("could not scan user row: %w", err)
+		}
+		if disabledAt.Valid {
+			user.DisabledAt = &disabledAt.Time
+		}
+		userList = append(userList, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating user rows: %w", err)
+	}
+	return userList, nil
+}
+
+// also revokes the user's sessions, so the new role is in effect on their next
+// login. both happen or neither does.
+func (store *DBStore) UpdateUserRole(id int, role users.Role) (*users.User, error) {
+	tx, err := store.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE users SET role = $2 WHERE id = $1 RETURNING ` + userColumns
+	user, err := scanUser(tx.QueryRow(query, id, role))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, users.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("could not update user role: %w", err)
+	}
+	if err := revokeUserSessions(tx, id, "role changed"); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("could not commit role change: %w", err)
+	}
+	return user, nil
+}
+
+// marks the address as verified, as long as it is still the one on the account
+func (store *DBStore) MarkEmailVerified(id int, email string) error {
+	res, err := store.DB.Exec(`UPDATE users SET email_verified = true WHERE id = $1 AND email = $2`, id, email)
+	if err != nil {
+		return fmt.Errorf("could not verify email: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return users.ErrUserNotFound
+	}
+	return nil
+}
+
+// records that a verification email goes out now, unless one went out less
+// than cooldown ago. reports whether it did.
+func (store *DBStore) ClaimVerificationEmail(id int, cooldown time.Duration) (bool, error) {
+	query := `UPDATE users SET verification_sent_at = now()
+		WHERE id = $1 AND (verification_sent_at IS NULL OR verification_sent_at <= now() - make_interval(secs => $2))`
+	res, err := store.DB.Exec(query, id, cooldown.Seconds())
+	if err != nil {
+		return false, fmt.Errorf("could not record verification email: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("could not record verification email: %w", err)
+	}
+	return n > 0, nil
+}
+
+// disables the account, or enables it again when disabled is false. disabling
+// also revokes the user's sessions, in the same transaction.
+func (store *DBStore) SetUserDisabled(id int, disabled bool) (*users.User, error) {
+	tx, err := store.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE users SET disabled_at = CASE WHEN $2 THEN COALESCE(disabled_at, now()) END
+		WHERE id = $1 RETURNING ` + userColumns
+	user, err := scanUser(tx.QueryRow(query, id, disabled))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, users.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("could not update user: %w", err)
+	}
+	if disabled {
+		if err := revokeUserSessions(tx, id, "account disabled"); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("could not commit account change: %w", err)
+	}
+	return user, nil
+}
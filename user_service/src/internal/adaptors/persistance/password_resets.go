@@ -0,0 +1,87 @@
+package persistance
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+	"user_service/internal/core/passwordreset"
+	"user_service/internal/core/users"
+)
+
+// stores a new reset token. earlier unused tokens of the user stop working,
+// so only the most recent email is valid. fails with ErrTooSoon if the user
+// got a token less than cooldown ago.
+func (store *DBStore) CreatePasswordResetToken(userID int, tokenHash string, expiresAt time.Time, cooldown time.Duration) error {
+	tx, err := store.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// locking the user makes concurrent requests take turns, so only one of them gets past the cooldown
+	if _, err := tx.Exec(`SELECT 1 FROM users WHERE id = $1 FOR UPDATE`, userID); err != nil {
+		return fmt.Errorf("could not lock user: %w", err)
+	}
+	var recent bool
+	query := `SELECT EXISTS (SELECT 1 FROM password_reset_tokens
+		WHERE user_id = $1 AND created_at > now() - make_interval(secs => $2))`
+	if err := tx.QueryRow(query, userID, cooldown.Seconds()).Scan(&recent); err != nil {
+		return fmt.Errorf("could not check earlier reset tokens: %w", err)
+	}
+	if recent {
+		return passwordreset.ErrTooSoon
+	}
+
+	if _, err := tx.Exec(`UPDATE password_reset_tokens SET used_at = now() WHERE user_id = $1 AND used_at IS NULL`, userID); err != nil {
+		return fmt.Errorf("could not invalidate earlier reset tokens: %w", err)
+	}
+	query = `INSERT INTO password_reset_tokens (user_id, token_hash, expires_at) VALUES ($1, $2, $3)`
+	if _, err := tx.Exec(query, userID, tokenHash, expiresAt); err != nil {
+		return fmt.Errorf("could not create reset token: %w", err)
+	}
+	return tx.Commit()
+}
+
+// marks an unused, unexpired reset token as used, sets the new password of its
+// user and revokes their sessions, in one transaction: if the password can't
+// be changed, the token stays usable.
+func (store *DBStore) ResetPasswordWithToken(tokenHash, passwordHash string) (*passwordreset.Token, error) {
+	tx, err := store.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// the row lock also makes concurrent requests with the same token wait, then fail
+	query := `UPDATE password_reset_tokens SET used_at = now()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > now()
+		RETURNING id, user_id, token_hash, expires_at, used_at`
+	token := &passwordreset.Token{}
+	var usedAt sql.NullTime
+	err = tx.QueryRow(query, tokenHash).Scan(&token.ID, &token.UserID, &token.TokenHash, &token.ExpiresAt, &usedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, passwordreset.ErrInvalidToken
+		}
+		return nil, fmt.Errorf("could not consume reset token: %w", err)
+	}
+	if usedAt.Valid {
+		token.UsedAt = &usedAt.Time
+	}
+
+	res, err := tx.Exec(`UPDATE users SET password_hash = $2 WHERE id = $1`, token.UserID, passwordHash)
+	if err != nil {
+		return nil, fmt.Errorf("could not update password: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return nil, users.ErrUserNotFound
+	}
+	// whoever knew the old password may still be logged in
+	if err := revokeUserSessions(tx, token.UserID, "password reset"); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("could not commit password reset: %w", err)
+	}
+	return token, nil
+}
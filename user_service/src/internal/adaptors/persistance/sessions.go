@@ -0,0 +1,127 @@
+package persistance
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+	"user_service/internal/core/sessions"
+)
+
+func (store *DBStore) CreateSession(userID int) (*sessions.Session, error) {
+	session := &sessions.Session{UserID: userID}
+	query := `INSERT INTO sessions (user_id) VALUES ($1) RETURNING id, created_at`
+	if err := store.DB.QueryRow(query, userID).Scan(&session.ID, &session.CreatedAt); err != nil {
+		return nil, fmt.Errorf("could not create session: %w", err)
+	}
+	return session, nil
+}
+
+func (store *DBStore) GetSession(id string) (*sessions.Session, error) {
+	session := &sessions.Session{}
+	var revokedAt sql.NullTime
+	var revokedReason sql.NullString
+	query := `SELECT id, user_id, created_at, revoked_at, revoked_reason FROM sessions WHERE id = $1`
+	err := store.DB.QueryRow(query, id).Scan(&session.ID, &session.UserID, &session.CreatedAt, &revokedAt, &revokedReason)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sessions.ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("could not get session: %w", err)
+	}
+	if revokedAt.Valid {
+		session.RevokedAt = &revokedAt.Time
+	}
+	session.RevokedReason = revokedReason.String
+	return session, nil
+}
+
+// revokes the session, which invalidates its whole refresh token family. revoking twice keeps the first reason.
+func (store *DBStore) RevokeSession(id string, reason string) error {
+	query := `UPDATE sessions SET revoked_at = now(), revoked_reason = $2 WHERE id = $1 AND revoked_at IS NULL`
+	if _, err := store.DB.Exec(query, id, reason); err != nil {
+		return fmt.Errorf("could not revoke session: %w", err)
+	}
+	return nil
+}
+
+const revokeUserSessionsQuery = `UPDATE sessions SET revoked_at = now(), revoked_reason = $2
+	WHERE user_id = $1 AND revoked_at IS NULL`
+
+// revokes every active session of the user, e.g. when the account is disabled
+func (store *DBStore) RevokeUserSessions(userID int, reason string) error {
+	if _, err := store.DB.Exec(revokeUserSessionsQuery, userID, reason); err != nil {
+		return fmt.Errorf("could not revoke sessions of user %d: %w", userID, err)
+	}
+	return nil
+}
+
+// like RevokeUserSessions, as part of a change made in tx
+func revokeUserSessions(tx *sql.Tx, userID int, reason string) error {
+	if _, err := tx.Exec(revokeUserSessionsQuery, userID, reason); err != nil {
+		return fmt.Errorf("could not revoke sessions of user %d: %w", userID, err)
+	}
+	return nil
+}
+
+func (store *DBStore) CreateRefreshToken(sessionID string, tokenHash string, expiresAt time.Time) error {
+	query := `INSERT INTO refresh_tokens (session_id, token_hash, expires_at) VALUES ($1, $2, $3)`
+	if _, err := store.DB.Exec(query, sessionID, tokenHash, expiresAt); err != nil {
+		return fmt.Errorf("could not create refresh token: %w", err)
+	}
+	return nil
+}
+
+// marks an unused refresh token as used and stores its successor in the same
+// session, in one transaction: a failed insert leaves the old token usable, so
+// a retry isn't mistaken for reuse. of two concurrent calls with the same token
+// only one succeeds.
+func (store *DBStore) RotateRefreshToken(tokenHash, nextHash string, nextExpiresAt time.Time) (*sessions.RefreshToken, error) {
+	tx, err := store.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE refresh_tokens SET used_at = now() WHERE token_hash = $1 AND used_at IS NULL
+		RETURNING id, session_id, token_hash, expires_at, used_at`
+	token, err := scanRefreshToken(tx.QueryRow(query, tokenHash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sessions.ErrInvalidRefreshToken
+		}
+		return nil, fmt.Errorf("could not consume refresh token: %w", err)
+	}
+	_, err = tx.Exec(`INSERT INTO refresh_tokens (session_id, token_hash, expires_at) VALUES ($1, $2, $3)`,
+		token.SessionID, nextHash, nextExpiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("could not create refresh token: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("could not commit refresh token rotation: %w", err)
+	}
+	return token, nil
+}
+
+func (store *DBStore) GetRefreshToken(tokenHash string) (*sessions.RefreshToken, error) {
+	query := `SELECT id, session_id, token_hash, expires_at, used_at FROM refresh_tokens WHERE token_hash = $1`
+	token, err := scanRefreshToken(store.DB.QueryRow(query, tokenHash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sessions.ErrInvalidRefreshToken
+		}
+		return nil, fmt.Errorf("could not get refresh token: %w", err)
+	}
+	return token, nil
+}
+
+func scanRefreshToken(row *sql.Row) (*sessions.RefreshToken, error) {
+	token := &sessions.RefreshToken{}
+	var usedAt sql.NullTime
+	if err := row.Scan(&token.ID, &token.SessionID, &token.TokenHash, &token.ExpiresAt, &usedAt); err != nil {
+		return nil, err
+	}
+	if usedAt.Valid {
+		token.UsedAt = &usedAt.Time
+	}
+	return token, nil
+}
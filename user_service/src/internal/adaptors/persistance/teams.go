@@ -0,0 +1,230 @@
+package persistance
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"user_service/internal/core/teams"
+
+	"github.com/lib/pq"
+)
+
+// creates the team with its creator as the first owner
+func (store *DBStore) CreateTeam(team *teams.Team) error {
+	tx, err := store.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO teams (name, created_by) VALUES ($1, $2) RETURNING id, created_at`
+	if err := tx.QueryRow(query, team.Name, team.CreatedBy).Scan(&team.ID, &team.CreatedAt); err != nil {
+		return fmt.Errorf("could not create team: %w", err)
+	}
+	query = `INSERT INTO team_members (team_id, user_id, role) VALUES ($1, $2, $3)`
+	if _, err := tx.Exec(query, team.ID, team.CreatedBy, teams.MemberRoleOwner); err != nil {
+		return fmt.Errorf("could not add team owner: %w", err)
+	}
+	return tx.Commit()
+}
+
+func (store *DBStore) GetTeam(id int) (*teams.Team, error) {
+	team := &teams.Team{}
+	query := `SELECT id, name, created_by, created_at FROM teams WHERE id = $1`
+	err := store.DB.QueryRow(query, id).Scan(&team.ID, &team.Name, &team.CreatedBy, &team.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, teams.ErrTeamNotFound
+		}
+		return nil, fmt.Errorf("could not get team: %w", err)
+	}
+	return team, nil
+}
+
+// the teams the user belongs to, oldest membership first
+func (store *DBStore) ListTeamsForUser(userID int) ([]*teams.Membership, error) {
+	query := `SELECT t.id, t.name, t.created_by, t.created_at, m.role, m.joined_at
+		FROM team_members m JOIN teams t ON t.id = m.team_id
+		WHERE m.user_id = $1 ORDER BY m.joined_at, t.id`
+	rows, err := store.DB.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("could not list teams: %w", err)
+	}
+	defer rows.Close()
+	var memberships []*teams.Membership
+	for rows.Next() {
+		m := &teams.Membership{}
+		if err := rows.Scan(&m.Team.ID, &m.Team.Name, &m.Team.CreatedBy, &m.Team.CreatedAt, &m.Role, &m.JoinedAt); err != nil {
+			return nil, fmt.Errorf("could not scan team row: %w", err)
+		}
+		memberships = append(memberships, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating team rows: %w", err)
+	}
+	return memberships, nil
+}
+
+func (store *DBStore) GetMemberRole(teamID, userID int) (teams.MemberRole, error) {
+	var role teams.MemberRole
+	query := `SELECT role FROM team_members WHERE team_id = $1 AND user_id = $2`
+	if err := store.DB.QueryRow(query, teamID, userID).Scan(&role); err != nil {
+		if err == sql.ErrNoRows {
+			return "", teams.ErrNotMember
+		}
+		return "", fmt.Errorf("could not get team membership: %w", err)
+	}
+	return role, nil
+}
+
+func (store *DBStore) ListMembers(teamID int) ([]*teams.Member, error) {
+	query := `SELECT u.id, u.username, u.email, m.role, m.joined_at
+		FROM team_members m JOIN users u ON u.id = m.user_id
+		WHERE m.team_id = $1 ORDER BY m.joined_at, u.id`
+	rows, err := store.DB.Query(query, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("could not list team members: %w", err)
+	}
+	defer rows.Close()
+	var members []*teams.Member
+	for rows.Next() {
+		m := &teams.Member{}
+		if err := rows.Scan(&m.UserID, &m.Username, &m.Email, &m.Role, &m.JoinedAt); err != nil {
+			return nil, fmt.Errorf("could not scan member row: %w", err)
+		}
+		members = append(members, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating member rows: %w", err)
+	}
+	return members, nil
+}
+
+// fails with ErrLastOwner when it would leave the team without an owner
+func (store *DBStore) UpdateMemberRole(teamID, userID int, role teams.MemberRole) error {
+	tx, err := store.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if role != teams.MemberRoleOwner {
+		if err := checkNotLastOwner(tx, teamID, userID); err != nil {
+			return err
+		}
+	}
+	res, err := tx.Exec(`UPDATE team_members SET role = $3 WHERE team_id = $1 AND user_id = $2`, teamID, userID, role)
+	if err != nil {
+		return fmt.Errorf("could not update member role: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return teams.ErrNotMember
+	}
+	return tx.Commit()
+}
+
+// fails with ErrLastOwner when it would leave the team without an owner
+func (store *DBStore) RemoveMember(teamID, userID int) error {
+	tx, err := store.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := checkNotLastOwner(tx, teamID, userID); err != nil {
+		return err
+	}
+	res, err := tx.Exec(`DELETE FROM team_members WHERE team_id = $1 AND user_id = $2`, teamID, userID)
+	if err != nil {
+		return fmt.Errorf("could not remove member: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return teams.ErrNotMember
+	}
+	return tx.Commit()
+}
+
+// fails with ErrLastOwner if userID is the team's only owner. the owner rows
+// stay locked until tx ends, so two owners can't demote each other at once.
+func checkNotLastOwner(tx *sql.Tx, teamID, userID int) error {
+	rows, err := tx.Query(`SELECT user_id FROM team_members WHERE team_id = $1 AND role = $2 FOR UPDATE`,
+		teamID, teams.MemberRoleOwner)
+	if err != nil {
+		return fmt.Errorf("could not lock team owners: %w", err)
+	}
+	defer rows.Close()
+
+	isOwner, others := false, 0
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return fmt.Errorf("could not scan team owner: %w", err)
+		}
+		if id == userID {
+			isOwner = true
+		} else {
+			others++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("could not lock team owners: %w", err)
+	}
+	if isOwner && others == 0 {
+		return teams.ErrLastOwner
+	}
+	return nil
+}
+
+func (store *DBStore) CreateInvite(invite *teams.Invite) error {
+	query := `INSERT INTO team_invites (team_id, email, role, invited_by, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at`
+	err := store.DB.QueryRow(query, invite.TeamID, invite.Email, invite.Role, invite.InvitedBy, invite.TokenHash, invite.ExpiresAt).
+		Scan(&invite.ID, &invite.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("could not create invite: %w", err)
+	}
+	return nil
+}
+
+// an open invite: not yet accepted and not expired
+func (store *DBStore) GetOpenInvite(tokenHash string) (*teams.Invite, error) {
+	invite := &teams.Invite{}
+	query := `SELECT id, team_id, email, role, invited_by, token_hash, expires_at, created_at
+		FROM team_invites WHERE token_hash = $1 AND accepted_at IS NULL AND expires_at > now()`
+	err := store.DB.QueryRow(query, tokenHash).Scan(&invite.ID, &invite.TeamID, &invite.Email, &invite.Role,
+		&invite.InvitedBy, &invite.TokenHash, &invite.ExpiresAt, &invite.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, teams.ErrInvalidInvite
+		}
+		return nil, fmt.Errorf("could not get invite: %w", err)
+	}
+	return invite, nil
+}
+
+// marks the invite accepted and adds the user to the team in one transaction.
+// of two concurrent accepts only one succeeds.
+func (store *DBStore) AcceptInvite(invite *teams.Invite, userID int) error {
+	tx, err := store.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`UPDATE team_invites SET accepted_at = now() WHERE id = $1 AND accepted_at IS NULL`, invite.ID)
+	if err != nil {
+		return fmt.Errorf("could not accept invite: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return teams.ErrInvalidInvite
+	}
+	query := `INSERT INTO team_members (team_id, user_id, role) VALUES ($1, $2, $3)`
+	if _, err := tx.Exec(query, invite.TeamID, userID, invite.Role); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" { // unique_violation
+			return teams.ErrAlreadyMember
+		}
+		return fmt.Errorf("could not add member: %w", err)
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,47 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"shared/events"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// hands email requests to the notification service over a redis stream
+type EmailPublisher struct {
+	client *redis.Client
+}
+
+func NewEmailPublisher(address string) (*EmailPublisher, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr: address,
+	})
+
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		return nil, fmt.Errorf("could not connect to redis: %w", err)
+	}
+
+	return &EmailPublisher{client: client}, nil
+}
+
+func (p *EmailPublisher) PublishEmail(email *events.Email) error {
+	payload, err := events.EncodeEmail(email)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err = p.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: events.EmailStream,
+		MaxLen: 10_000, // the links inside are short-lived anyway
+		Approx: true,
+		Values: map[string]interface{}{events.PayloadField: payload},
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("could not publish email to stream: %w", err)
+	}
+	return nil
+}
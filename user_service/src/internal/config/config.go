@@ -1,7 +1,9 @@
 package config
 
 import (
+	"fmt"
 	"log"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -12,6 +14,22 @@ type Config struct {
 	ServerAddress     string `mapstructure:"SERVER_ADDRESS"`
 	GRPCServerAddress string `mapstructure:"GRPC_SERVER_ADDRESS"`
 	JWTSecretKey      string `mapstructure:"JWT_SECRET_KEY"`
+
+	AccessTokenTTL  time.Duration `mapstructure:"ACCESS_TOKEN_TTL"`
+	RefreshTokenTTL time.Duration `mapstructure:"REFRESH_TOKEN_TTL"`
+
+	RedisAddress          string        `mapstructure:"REDIS_ADDRESS"`
+	PasswordResetURL      string        `mapstructure:"PASSWORD_RESET_URL"` // page the emailed link opens; gets ?token= appended
+	PasswordResetTTL      time.Duration `mapstructure:"PASSWORD_RESET_TTL"`
+	PasswordResetCooldown time.Duration `mapstructure:"PASSWORD_RESET_COOLDOWN"` // least time between two reset emails to one user
+
+	EmailVerificationURL      string        `mapstructure:"EMAIL_VERIFICATION_URL"` // gets ?token= appended
+	EmailVerificationTTL      time.Duration `mapstructure:"EMAIL_VERIFICATION_TTL"`
+	EmailVerificationCooldown time.Duration `mapstructure:"EMAIL_VERIFICATION_COOLDOWN"` // least time between two verification emails to one user
+	RequireVerifiedEmail      bool          `mapstructure:"REQUIRE_VERIFIED_EMAIL"`      // blocks login until the email is verified
+
+	TeamInviteURL string        `mapstructure:"TEAM_INVITE_URL"` // page the invite link opens; gets ?token= appended
+	TeamInviteTTL time.Duration `mapstructure:"TEAM_INVITE_TTL"`
 }
 
 func LoadConfig(path string) (config Config, err error) {
@@ -20,6 +38,20 @@ func LoadConfig(path string) (config Config, err error) {
 	viper.SetConfigType("env")
 	viper.AutomaticEnv()
 
+	// defaults also make viper pick these keys up from the environment
+	viper.SetDefault("ACCESS_TOKEN_TTL", 15*time.Minute)
+	viper.SetDefault("REFRESH_TOKEN_TTL", 30*24*time.Hour)
+	viper.SetDefault("REDIS_ADDRESS", "localhost:6379")
+	viper.SetDefault("PASSWORD_RESET_URL", "http://localhost:3000/reset-password")
+	viper.SetDefault("PASSWORD_RESET_TTL", time.Hour)
+	viper.SetDefault("PASSWORD_RESET_COOLDOWN", 5*time.Minute)
+	viper.SetDefault("EMAIL_VERIFICATION_URL", "http://localhost:8080/verify-email")
+	viper.SetDefault("EMAIL_VERIFICATION_TTL", 48*time.Hour)
+	viper.SetDefault("EMAIL_VERIFICATION_COOLDOWN", 5*time.Minute)
+	viper.SetDefault("REQUIRE_VERIFIED_EMAIL", false)
+	viper.SetDefault("TEAM_INVITE_URL", "http://localhost:3000/accept-invite")
+	viper.SetDefault("TEAM_INVITE_TTL", 7*24*time.Hour)
+
 	err = viper.ReadInConfig()
 	if err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
@@ -29,6 +61,21 @@ func LoadConfig(path string) (config Config, err error) {
 		}
 	}
 
-	err = viper.Unmarshal(&config)
+	if err = viper.Unmarshal(&config); err != nil {
+		return
+	}
+	err = config.validate()
 	return
 }
+
+// HS256 keys shorter than this can be brute-forced from a single token
+const MinJWTSecretLength = 32
+
+// rejects settings the service can't safely run with. an unset secret would
+// let anyone sign tokens.
+func (c Config) validate() error {
+	if len(c.JWTSecretKey) < MinJWTSecretLength {
+		return fmt.Errorf("JWT_SECRET_KEY must be at least %d bytes, got %d", MinJWTSecretLength, len(c.JWTSecretKey))
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package passwordreset
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid or expired password reset token")
+	ErrTooSoon      = errors.New("a password reset token was issued too recently")
+)
+
+// single-use token emailed to a user who forgot their password, stored only as a hash
+type Token struct {
+	ID        int64
+	UserID    int
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
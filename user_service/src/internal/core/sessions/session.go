@@ -0,0 +1,43 @@
+package sessions
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrSessionNotFound     = errors.New("session not found")
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token already used, session revoked")
+	ErrSessionRevoked      = errors.New("session has been revoked")
+)
+
+// a login. every refresh token issued for it belongs to the same family,
+// and revoking the session invalidates all of them plus its access tokens.
+type Session struct {
+	ID            string
+	UserID        int
+	CreatedAt     time.Time
+	RevokedAt     *time.Time
+	RevokedReason string
+}
+
+func (s *Session) IsRevoked() bool {
+	return s.RevokedAt != nil
+}
+
+// single-use refresh token, stored only as a hash
+type RefreshToken struct {
+	ID        int64
+	SessionID string
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
+
+// the tokens handed out on login and refresh
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    time.Duration // lifetime of the access token
+}
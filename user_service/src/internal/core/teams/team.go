@@ -0,0 +1,108 @@
+package teams
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// domain errors, checked with errors.Is by the handlers to pick a status code.
+var (
+	ErrTeamNotFound      = errors.New("team not found")
+	ErrInvalidTeamName   = errors.New("team name must be between 1 and 100 characters, without control characters")
+	ErrNotMember         = errors.New("not a member of the team")
+	ErrForbidden         = errors.New("your team role does not allow this")
+	ErrInvalidMemberRole = errors.New("invalid member role")
+	ErrAlreadyMember     = errors.New("user is already a member of the team")
+	ErrLastOwner         = errors.New("a team needs at least one owner")
+	ErrInvalidInvite     = errors.New("invalid or expired invite")
+	ErrInviteEmail       = errors.New("invite was sent to a different email address")
+	ErrInviteUnverified  = errors.New("verify your email address before accepting an invite")
+)
+
+// an organization users work in together. tasks can be shared with a team.
+type Team struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	CreatedBy int       `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// the name ends up in invite emails, so line breaks and the like are refused
+func ValidateName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 100 {
+		return "", ErrInvalidTeamName
+	}
+	if strings.IndexFunc(name, unicode.IsControl) >= 0 {
+		return "", ErrInvalidTeamName
+	}
+	return name, nil
+}
+
+// role of a user within one team, independent of their account role
+type MemberRole string
+
+const (
+	MemberRoleOwner  MemberRole = "owner"
+	MemberRoleAdmin  MemberRole = "admin"
+	MemberRoleMember MemberRole = "member"
+)
+
+func ParseMemberRole(s string) (MemberRole, error) {
+	role := MemberRole(s)
+	if !role.IsValid() {
+		return "", fmt.Errorf("%w %q: must be one of owner, admin, member", ErrInvalidMemberRole, s)
+	}
+	return role, nil
+}
+
+func (r MemberRole) IsValid() bool {
+	switch r {
+	case MemberRoleOwner, MemberRoleAdmin, MemberRoleMember:
+		return true
+	}
+	return false
+}
+
+// owners and admins invite and remove members
+func (r MemberRole) CanManageMembers() bool {
+	return r == MemberRoleOwner || r == MemberRoleAdmin
+}
+
+// only owners change roles, so nobody can promote themselves
+func (r MemberRole) CanChangeRoles() bool {
+	return r == MemberRoleOwner
+}
+
+// a user as seen from a team
+type Member struct {
+	UserID   int        `json:"user_id"`
+	Username string     `json:"username"`
+	Email    string     `json:"email"`
+	Role     MemberRole `json:"role"`
+	JoinedAt time.Time  `json:"joined_at"`
+}
+
+// a team as seen from one of its members
+type Membership struct {
+	Team     Team       `json:"team"`
+	Role     MemberRole `json:"role"`
+	JoinedAt time.Time  `json:"joined_at"`
+}
+
+// invitation emailed to someone, who may not have an account yet.
+// the token is stored only as a hash.
+type Invite struct {
+	ID         int64      `json:"id"`
+	TeamID     int        `json:"team_id"`
+	Email      string     `json:"email"`
+	Role       MemberRole `json:"role"`
+	InvitedBy  int        `json:"invited_by"`
+	TokenHash  string     `json:"-"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
@@ -0,0 +1,15 @@
+package users
+
+import "errors"
+
+var (
+	ErrUserNotFound             = errors.New("user not found")
+	ErrUserDisabled             = errors.New("account is disabled")
+	ErrUsernameTaken            = errors.New("username is already taken")
+	ErrCannotModifySelf         = errors.New("admins cannot change their own role or disable themselves")
+	ErrPasswordTooShort         = errors.New("password must be at least 8 characters")
+	ErrEmailNotVerified         = errors.New("email address has not been verified")
+	ErrInvalidVerificationToken = errors.New("invalid or expired verification token")
+)
+
+const MinPasswordLength = 8
@@ -0,0 +1,58 @@
+package users
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidRole = errors.New("invalid role")
+
+type Role string
+
+const (
+	RoleUser    Role = "user"
+	RoleManager Role = "manager"
+	RoleAdmin   Role = "admin"
+)
+
+// new accounts start as plain users; other roles are only granted by an admin
+const DefaultRole = RoleUser
+
+// something a role may be allowed to do
+type Permission string
+
+const (
+	PermListUsers    Permission = "users:list"
+	PermManageUsers  Permission = "users:manage" // change roles, disable accounts
+	PermReadAllTasks Permission = "tasks:read_all"
+	PermEditAllTasks Permission = "tasks:edit_all"
+)
+
+// what each role may do on top of managing its own account and tasks
+var rolePermissions = map[Role][]Permission{
+	RoleUser:    {},
+	RoleManager: {PermReadAllTasks},
+	RoleAdmin:   {PermListUsers, PermManageUsers, PermReadAllTasks, PermEditAllTasks},
+}
+
+func ParseRole(s string) (Role, error) {
+	role := Role(s)
+	if !role.IsValid() {
+		return "", fmt.Errorf("%w %q: must be one of user, manager, admin", ErrInvalidRole, s)
+	}
+	return role, nil
+}
+
+func (r Role) IsValid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+func (r Role) Can(p Permission) bool {
+	for _, granted := range rolePermissions[r] {
+		if granted == p {
+			return true
+		}
+	}
+	return false
+}
@@ -1,9 +1,18 @@
 package users
 
+import "time"
+
 type User struct {
-	ID           int    `json:"id"`
-	Username     string `json:"username"`
-	Email        string `json:"email"`
-	Password     string `json:"-"` // preventing password from being marshalled into JSON response.
-	PasswordHash string `json:"-"`
+	ID            int        `json:"id"`
+	Username      string     `json:"username"`
+	Email         string     `json:"email"`
+	EmailVerified bool       `json:"email_verified"`
+	Role          Role       `json:"role"`
+	DisabledAt    *time.Time `json:"disabled_at,omitempty"` // disabled accounts can't log in
+	Password      string     `json:"-"`                     // preventing password from being marshalled into JSON response.
+	PasswordHash  string     `json:"-"`
+}
+
+func (u *User) IsDisabled() bool {
+	return u.DisabledAt != nil
 }
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"user_service/internal/core/users"
+	"user_service/internal/interfaces/input/api/rest/middleware"
+	"user_service/internal/usecase"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type ListUsersResponse struct {
+	Users     []*users.User `json:"users"`
+	NextAfter int           `json:"next_after,omitempty"` // pass as ?after= to get the next page
+}
+
+// for GET /admin/users?after=&limit=
+func (h *UserHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	afterID, limit := 0, usecase.MaxListUsersLimit
+	var err error
+	if v := r.URL.Query().Get("after"); v != "" {
+		if afterID, err = strconv.Atoi(v); err != nil {
+			http.Error(w, "Invalid after parameter", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit < 1 || limit > usecase.MaxListUsersLimit {
+			http.Error(w, "limit must be between 1 and "+strconv.Itoa(usecase.MaxListUsersLimit), http.StatusBadRequest)
+			return
+		}
+	}
+
+	userList, err := h.userUsecase.ListUsers(afterID, limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	res := ListUsersResponse{Users: userList}
+	if res.Users == nil {
+		res.Users = []*users.User{}
+	}
+	// a short page means there is nothing after it
+	if len(userList) == limit {
+		res.NextAfter = userList[len(userList)-1].ID
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(res)
+}
+
+type ChangeRoleRequest struct {
+	Role string `json:"role"`
+}
+
+// for PUT /admin/users/{id}/role
+func (h *UserHandler) ChangeRole(w http.ResponseWriter, r *http.Request) {
+	actorID, userID, ok := adminTarget(w, r)
+	if !ok {
+		return
+	}
+	var req ChangeRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	role, err := users.ParseRole(req.Role)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	user, err := h.userUsecase.ChangeRole(actorID, userID, role)
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(user)
+}
+
+// for POST /admin/users/{id}/disable
+func (h *UserHandler) DisableUser(w http.ResponseWriter, r *http.Request) {
+	h.setUserDisabled(w, r, true)
+}
+
+// for POST /admin/users/{id}/enable
+func (h *UserHandler) EnableUser(w http.ResponseWriter, r *http.Request) {
+	h.setUserDisabled(w, r, false)
+}
+
+func (h *UserHandler) setUserDisabled(w http.ResponseWriter, r *http.Request, disabled bool) {
+	actorID, userID, ok := adminTarget(w, r)
+	if !ok {
+		return
+	}
+	user, err := h.userUsecase.SetUserDisabled(actorID, userID, disabled)
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(user)
+}
+
+// reads the calling admin from the context and the target user from the url.
+// writes the error response itself when either is missing.
+func adminTarget(w http.ResponseWriter, r *http.Request) (actorID, userID int, ok bool) {
+	actorID, ok = r.Context().Value(middleware.UserIDContextKey).(int)
+	if !ok {
+		http.Error(w, "Could not retrieve user ID from context", http.StatusInternalServerError)
+		return 0, 0, false
+	}
+	userID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	return actorID, userID, true
+}
+
+func writeAdminError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, users.ErrUserNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, users.ErrInvalidRole):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	case errors.Is(err, users.ErrCannotModifySelf):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"user_service/internal/core/passwordreset"
+	"user_service/internal/core/users"
+	"user_service/internal/usecase"
+)
+
+type PasswordHandler struct {
+	resetUsecase usecase.PasswordResetUsecase
+}
+
+func NewPasswordHandler(uc usecase.PasswordResetUsecase) *PasswordHandler {
+	return &PasswordHandler{
+		resetUsecase: uc,
+	}
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+// for POST /password/forgot. always answers 202, whether or not the email is known.
+func (h *PasswordHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := h.resetUsecase.ForgotPassword(req.Email); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{"message": "If the email belongs to an account, a reset link has been sent"})
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// for POST /password/reset
+func (h *PasswordHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := h.resetUsecase.ResetPassword(req.Token, req.Password); err != nil {
+		switch {
+		case errors.Is(err, passwordreset.ErrInvalidToken), errors.Is(err, users.ErrPasswordTooShort):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Password has been reset, please log in again"})
+}
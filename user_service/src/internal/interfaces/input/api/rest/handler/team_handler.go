@@ -0,0 +1,229 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"user_service/internal/core/teams"
+	"user_service/internal/interfaces/input/api/rest/middleware"
+	"user_service/internal/usecase"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type TeamHandler struct {
+	teamUsecase usecase.TeamUsecase
+}
+
+func NewTeamHandler(uc usecase.TeamUsecase) *TeamHandler {
+	return &TeamHandler{
+		teamUsecase: uc,
+	}
+}
+
+type CreateTeamRequest struct {
+	Name string `json:"name"`
+}
+
+// for POST /teams. the caller becomes the team's owner.
+func (h *TeamHandler) CreateTeam(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := r.Context().Value(middleware.UserIDContextKey).(int)
+	if !ok {
+		http.Error(w, "Could not retrieve user ID from context", http.StatusInternalServerError)
+		return
+	}
+	var req CreateTeamRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	team, err := h.teamUsecase.CreateTeam(actorID, req.Name)
+	if err != nil {
+		writeTeamError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, team)
+}
+
+// for GET /teams, the caller's teams
+func (h *TeamHandler) ListTeams(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := r.Context().Value(middleware.UserIDContextKey).(int)
+	if !ok {
+		http.Error(w, "Could not retrieve user ID from context", http.StatusInternalServerError)
+		return
+	}
+	memberships, err := h.teamUsecase.ListTeams(actorID)
+	if err != nil {
+		writeTeamError(w, err)
+		return
+	}
+	if memberships == nil {
+		memberships = []*teams.Membership{}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"teams": memberships})
+}
+
+type TeamResponse struct {
+	*teams.Team
+	Members []*teams.Member `json:"members"`
+}
+
+// for GET /teams/{id}
+func (h *TeamHandler) GetTeam(w http.ResponseWriter, r *http.Request) {
+	actorID, teamID, ok := teamRequest(w, r)
+	if !ok {
+		return
+	}
+	team, members, err := h.teamUsecase.GetTeam(actorID, teamID)
+	if err != nil {
+		writeTeamError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, TeamResponse{Team: team, Members: members})
+}
+
+type InviteRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"` // defaults to member
+}
+
+// for POST /teams/{id}/invites. the invite link is emailed, not returned.
+func (h *TeamHandler) InviteMember(w http.ResponseWriter, r *http.Request) {
+	actorID, teamID, ok := teamRequest(w, r)
+	if !ok {
+		return
+	}
+	var req InviteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	role := teams.MemberRoleMember
+	if req.Role != "" {
+		var err error
+		if role, err = teams.ParseMemberRole(req.Role); err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+	}
+	invite, err := h.teamUsecase.InviteMember(actorID, teamID, req.Email, role)
+	if err != nil {
+		writeTeamError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, invite)
+}
+
+type AcceptInviteRequest struct {
+	Token string `json:"token"`
+}
+
+// for POST /teams/invites/accept
+func (h *TeamHandler) AcceptInvite(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := r.Context().Value(middleware.UserIDContextKey).(int)
+	if !ok {
+		http.Error(w, "Could not retrieve user ID from context", http.StatusInternalServerError)
+		return
+	}
+	var req AcceptInviteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	membership, err := h.teamUsecase.AcceptInvite(actorID, req.Token)
+	if err != nil {
+		writeTeamError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, membership)
+}
+
+type ChangeMemberRoleRequest struct {
+	Role string `json:"role"`
+}
+
+// for PUT /teams/{id}/members/{user_id}
+func (h *TeamHandler) ChangeMemberRole(w http.ResponseWriter, r *http.Request) {
+	actorID, teamID, ok := teamRequest(w, r)
+	if !ok {
+		return
+	}
+	userID, err := strconv.Atoi(chi.URLParam(r, "user_id"))
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+	var req ChangeMemberRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	role, err := teams.ParseMemberRole(req.Role)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	if err := h.teamUsecase.ChangeMemberRole(actorID, teamID, userID, role); err != nil {
+		writeTeamError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// for DELETE /teams/{id}/members/{user_id}
+func (h *TeamHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	actorID, teamID, ok := teamRequest(w, r)
+	if !ok {
+		return
+	}
+	userID, err := strconv.Atoi(chi.URLParam(r, "user_id"))
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+	if err := h.teamUsecase.RemoveMember(actorID, teamID, userID); err != nil {
+		writeTeamError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// reads the caller from the context and the team from the url.
+// writes the error response itself when either is missing.
+func teamRequest(w http.ResponseWriter, r *http.Request) (actorID, teamID int, ok bool) {
+	actorID, ok = r.Context().Value(middleware.UserIDContextKey).(int)
+	if !ok {
+		http.Error(w, "Could not retrieve user ID from context", http.StatusInternalServerError)
+		return 0, 0, false
+	}
+	teamID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid team ID", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	return actorID, teamID, true
+}
+
+func writeTeamError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, teams.ErrTeamNotFound), errors.Is(err, teams.ErrNotMember):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, teams.ErrForbidden), errors.Is(err, teams.ErrInviteEmail), errors.Is(err, teams.ErrInviteUnverified):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, teams.ErrInvalidTeamName), errors.Is(err, teams.ErrInvalidMemberRole):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	case errors.Is(err, teams.ErrAlreadyMember), errors.Is(err, teams.ErrLastOwner):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, teams.ErrInvalidInvite):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -2,7 +2,9 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"user_service/internal/core/sessions"
 	"user_service/internal/core/users"
 	"user_service/internal/interfaces/input/api/rest/middleware"
 	"user_service/internal/usecase"
@@ -36,6 +38,10 @@ func (h *UserHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
 		Password: req.Password,
 	}
 	if err := h.userUsecase.RegisterUser(user); err != nil {
+		if errors.Is(err, users.ErrUsernameTaken) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -55,14 +61,73 @@ func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
-	token, err := h.userUsecase.LoginUser(req.Email, req.Password)
+	tokens, err := h.userUsecase.LoginUser(req.Email, req.Password)
 	if err != nil {
+		if errors.Is(err, users.ErrUserDisabled) || errors.Is(err, users.ErrEmailNotVerified) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusUnauthorized)
 		return
 	}
+	writeTokens(w, tokens)
+}
+
+type TokenResponse struct {
+	Token        string `json:"token"` // short-lived access token
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"` // access token lifetime in seconds
+}
+
+func writeTokens(w http.ResponseWriter, tokens *sessions.TokenPair) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{"token": token})
+	json.NewEncoder(w).Encode(TokenResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    int(tokens.ExpiresIn.Seconds()),
+	})
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// for POST /token/refresh. the refresh token in the request is used up and replaced.
+func (h *UserHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
+	var req RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	tokens, err := h.userUsecase.RefreshTokens(req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, sessions.ErrInvalidRefreshToken) || errors.Is(err, sessions.ErrRefreshTokenReused) || errors.Is(err, sessions.ErrSessionRevoked) {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		if errors.Is(err, users.ErrUserDisabled) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeTokens(w, tokens)
+}
+
+// for POST /logout. revokes the session of the token used to call it.
+func (h *UserHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	sessionID, ok := r.Context().Value(middleware.SessionIDContextKey).(string)
+	if !ok {
+		http.Error(w, "Could not retrieve session from context", http.StatusInternalServerError)
+		return
+	}
+	if err := h.userUsecase.Logout(sessionID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
 }
 
 func (h *UserHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"user_service/internal/core/users"
+	"user_service/internal/usecase"
+)
+
+type VerificationHandler struct {
+	verificationUsecase usecase.EmailVerificationUsecase
+}
+
+func NewVerificationHandler(uc usecase.EmailVerificationUsecase) *VerificationHandler {
+	return &VerificationHandler{
+		verificationUsecase: uc,
+	}
+}
+
+// for GET /verify-email?token=. this is the link in the verification email.
+func (h *VerificationHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		http.Error(w, "token is required", http.StatusBadRequest)
+		return
+	}
+	if err := h.verificationUsecase.VerifyEmail(token); err != nil {
+		if errors.Is(err, users.ErrInvalidVerificationToken) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Email verified successfully"})
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email"`
+}
+
+// for POST /verify-email/resend. always answers 202, whether or not the email is known.
+func (h *VerificationHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	var req ResendVerificationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := h.verificationUsecase.ResendVerificationEmail(req.Email); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{"message": "If the email belongs to an unverified account, a new link has been sent"})
+}
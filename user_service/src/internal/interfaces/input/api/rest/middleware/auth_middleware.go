@@ -4,15 +4,25 @@ import (
 	"context"
 	"net/http"
 	"strings"
+	"user_service/internal/core/users"
 	"user_service/pkg/generatejwt"
 )
 
 type contextKey string
 
-const UserIDContextKey = contextKey("userID")
+const (
+	UserIDContextKey    = contextKey("userID")
+	SessionIDContextKey = contextKey("sessionID")
+	RoleContextKey      = contextKey("role")
+)
+
+// tells whether a session is still valid, i.e. not logged out or revoked.
+type SessionChecker interface {
+	IsSessionActive(sessionID string) (bool, error)
+}
 
-// middleware for JWT authentication.
-func AuthMiddleware(jwtSecret string) func(http.Handler) http.Handler {
+// middleware for JWT authentication. tokens of revoked sessions are rejected.
+func AuthMiddleware(jwtSecret string, sessions SessionChecker) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			authHeader := r.Header.Get("Authorization")
@@ -36,8 +46,25 @@ func AuthMiddleware(jwtSecret string) func(http.Handler) http.Handler {
 				return
 			}
 
+			// tokens issued before sessions existed can't be revoked, so they are not accepted either
+			if claims.SessionID == "" {
+				http.Error(w, "Invalid token: no session, please log in again", http.StatusUnauthorized)
+				return
+			}
+			active, err := sessions.IsSessionActive(claims.SessionID)
+			if err != nil {
+				http.Error(w, "Could not check session", http.StatusInternalServerError)
+				return
+			}
+			if !active {
+				http.Error(w, "Invalid token: session has been revoked", http.StatusUnauthorized)
+				return
+			}
+
 			// if it reaches here, token is valid...hence, adding UserID to request context
 			ctx := context.WithValue(r.Context(), UserIDContextKey, claims.UserID)
+			ctx = context.WithValue(ctx, SessionIDContextKey, claims.SessionID)
+			ctx = context.WithValue(ctx, RoleContextKey, users.Role(claims.Role))
 
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
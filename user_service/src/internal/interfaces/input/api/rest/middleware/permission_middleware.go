@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"net/http"
+	"user_service/internal/core/users"
+)
+
+// middleware that only lets through callers whose role grants the permission.
+// must run after AuthMiddleware, which puts the role in the context.
+func RequirePermission(perm users.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role, ok := r.Context().Value(RoleContextKey).(users.Role)
+			if !ok {
+				http.Error(w, "Could not retrieve role from context", http.StatusInternalServerError)
+				return
+			}
+			if !role.Can(perm) {
+				http.Error(w, "Forbidden: missing permission "+string(perm), http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -2,17 +2,24 @@ package server
 
 import (
 	"context"
+	"errors"
+	"user_service/internal/core/teams"
+	"user_service/internal/core/users"
 	"user_service/internal/usecase"
 	pb "user_service/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type UserServer struct {
 	pb.UnimplementedUserServiceServer
 	userUsecase usecase.UserUsecase
+	teamUsecase usecase.TeamUsecase
 }
 
-func NewUserServer(uc usecase.UserUsecase) *UserServer {
-	return &UserServer{userUsecase: uc}
+func NewUserServer(uc usecase.UserUsecase, teamUC usecase.TeamUsecase) *UserServer {
+	return &UserServer{userUsecase: uc, teamUsecase: teamUC}
 }
 
 func (s *UserServer) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.GetUserResponse, error) {
@@ -20,12 +27,110 @@ func (s *UserServer) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.G
 
 	user, err := s.userUsecase.GetProfile(int(userID))
 	if err != nil {
+		if errors.Is(err, users.ErrUserNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
 		return nil, err
 	}
 
+	return toUserResponse(user), nil
+}
+
+func (s *UserServer) BatchGetUsers(ctx context.Context, req *pb.BatchGetUsersRequest) (*pb.BatchGetUsersResponse, error) {
+	found, missing, err := s.userUsecase.BatchGetUsers(toIntIDs(req.GetIds()))
+	if err != nil {
+		return nil, batchError(err)
+	}
+
+	res := &pb.BatchGetUsersResponse{MissingIds: toInt32IDs(missing)}
+	for _, user := range found {
+		res.Users = append(res.Users, toUserResponse(user))
+	}
+	return res, nil
+}
+
+func (s *UserServer) GetUsersByUsernames(ctx context.Context, req *pb.GetUsersByUsernamesRequest) (*pb.GetUsersByUsernamesResponse, error) {
+	found, missing, err := s.userUsecase.GetUsersByUsernames(req.GetUsernames())
+	if err != nil {
+		return nil, batchError(err)
+	}
+
+	res := &pb.GetUsersByUsernamesResponse{MissingUsernames: missing}
+	for _, user := range found {
+		res.Users = append(res.Users, toUserResponse(user))
+	}
+	return res, nil
+}
+
+func (s *UserServer) ValidateUsers(ctx context.Context, req *pb.ValidateUsersRequest) (*pb.ValidateUsersResponse, error) {
+	found, missing, err := s.userUsecase.BatchGetUsers(toIntIDs(req.GetIds()))
+	if err != nil {
+		return nil, batchError(err)
+	}
+
+	res := &pb.ValidateUsersResponse{InvalidIds: toInt32IDs(missing)}
+	for _, user := range found {
+		res.ValidIds = append(res.ValidIds, int32(user.ID))
+	}
+	return res, nil
+}
+
+func (s *UserServer) GetTeamMembership(ctx context.Context, req *pb.GetTeamMembershipRequest) (*pb.GetTeamMembershipResponse, error) {
+	role, err := s.teamUsecase.GetMemberRole(int(req.GetTeamId()), int(req.GetUserId()))
+	if errors.Is(err, teams.ErrNotMember) {
+		return &pb.GetTeamMembershipResponse{IsMember: false}, nil
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &pb.GetTeamMembershipResponse{IsMember: true, Role: string(role)}, nil
+}
+
+func (s *UserServer) ListUserTeams(ctx context.Context, req *pb.ListUserTeamsRequest) (*pb.ListUserTeamsResponse, error) {
+	memberships, err := s.teamUsecase.ListMemberships(int(req.GetUserId()))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	res := &pb.ListUserTeamsResponse{}
+	for _, m := range memberships {
+		res.Teams = append(res.Teams, &pb.TeamMembership{
+			TeamId:   int32(m.Team.ID),
+			TeamName: m.Team.Name,
+			Role:     string(m.Role),
+		})
+	}
+	return res, nil
+}
+
+func toUserResponse(user *users.User) *pb.GetUserResponse {
 	return &pb.GetUserResponse{
-		Id:       int32(user.ID),
-		Username: user.Username,
-		Email:    user.Email,
-	}, nil
+		Id:            int32(user.ID),
+		Username:      user.Username,
+		Email:         user.Email,
+		Role:          string(user.Role),
+		EmailVerified: user.EmailVerified,
+	}
+}
+
+func batchError(err error) error {
+	if errors.Is(err, usecase.ErrBatchTooLarge) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+func toIntIDs(ids []int32) []int {
+	out := make([]int, len(ids))
+	for i, id := range ids {
+		out[i] = int(id)
+	}
+	return out
+}
+
+func toInt32IDs(ids []int) []int32 {
+	out := make([]int32, len(ids))
+	for i, id := range ids {
+		out[i] = int32(id)
+	}
+	return out
 }
@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"shared/events"
+	"time"
+	"user_service/internal/core/users"
+	"user_service/pkg/generatejwt"
+)
+
+// verification links are signed tokens rather than rows in the database;
+// verifying twice is harmless, so they don't need to be single-use
+type emailVerificationUsecase struct {
+	userRepo     UserRepository
+	emails       EmailPublisher
+	jwtSecretKey string
+	verifyURL    string
+	tokenTTL     time.Duration
+	cooldown     time.Duration // least time between two verification emails to the same user
+}
+
+func NewEmailVerificationUsecase(userRepo UserRepository, emails EmailPublisher, jwtSecret, verifyURL string, tokenTTL, cooldown time.Duration) EmailVerificationUsecase {
+	return &emailVerificationUsecase{
+		userRepo:     userRepo,
+		emails:       emails,
+		jwtSecretKey: jwtSecret,
+		verifyURL:    verifyURL,
+		tokenTTL:     tokenTTL,
+		cooldown:     cooldown,
+	}
+}
+
+func (uc *emailVerificationUsecase) SendVerificationEmail(user *users.User) error {
+	// the last link is still on its way or still valid; asking again mustn't flood the inbox
+	send, err := uc.userRepo.ClaimVerificationEmail(user.ID, uc.cooldown)
+	if err != nil {
+		return err
+	}
+	if !send {
+		return nil
+	}
+	token, err := generatejwt.GenerateVerificationToken(user, uc.jwtSecretKey, uc.tokenTTL)
+	if err != nil {
+		return err
+	}
+	msg := events.NewEmail(events.EmailVerification, user.ID, user.Email, user.Username)
+	msg.Link = uc.verifyURL + "?token=" + url.QueryEscape(token)
+	msg.ExpiresAt = time.Now().Add(uc.tokenTTL).UTC()
+	return uc.emails.PublishEmail(msg)
+}
+
+func (uc *emailVerificationUsecase) VerifyEmail(token string) error {
+	claims, err := generatejwt.ValidateVerificationToken(token, uc.jwtSecretKey)
+	if err != nil {
+		return fmt.Errorf("%w: %v", users.ErrInvalidVerificationToken, err)
+	}
+	// no match means the account is gone or its address changed since the link was sent
+	if err := uc.userRepo.MarkEmailVerified(claims.UserID, claims.Email); err != nil {
+		if errors.Is(err, users.ErrUserNotFound) {
+			return users.ErrInvalidVerificationToken
+		}
+		return err
+	}
+	log.Printf("Email verified for user %d", claims.UserID)
+	return nil
+}
+
+// like ForgotPassword, the outcome is not revealed to the caller
+func (uc *emailVerificationUsecase) ResendVerificationEmail(email string) error {
+	user, err := uc.userRepo.GetUserByEmail(email)
+	if errors.Is(err, users.ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not look up user: %w", err)
+	}
+	if user.EmailVerified || user.IsDisabled() {
+		return nil
+	}
+	if err := uc.SendVerificationEmail(user); err != nil {
+		log.Printf("Could not send verification email for user %d: %v", user.ID, err)
+	}
+	return nil
+}
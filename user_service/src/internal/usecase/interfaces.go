@@ -1,12 +1,32 @@
 package usecase
 
-import "user_service/internal/core/users"
+import (
+	"shared/events"
+	"time"
+	"user_service/internal/core/passwordreset"
+	"user_service/internal/core/sessions"
+	"user_service/internal/core/teams"
+	"user_service/internal/core/users"
+)
 
 // business logic operations for users.
 type UserUsecase interface {
 	RegisterUser(user *users.User) error
-	LoginUser(email, password string) (string, error)
+	LoginUser(email, password string) (*sessions.TokenPair, error)
+	// swaps a refresh token for a new pair. reusing a refresh token revokes its whole session.
+	RefreshTokens(refreshToken string) (*sessions.TokenPair, error)
+	Logout(sessionID string) error
+	IsSessionActive(sessionID string) (bool, error)
 	GetProfile(userID int) (*users.User, error)
+	// found users in id order, plus the requested ids that don't exist
+	BatchGetUsers(userIDs []int) (found []*users.User, missing []int, err error)
+	// active users with the given usernames, ignoring case, plus the usernames nobody has
+	GetUsersByUsernames(usernames []string) (found []*users.User, missing []string, err error)
+
+	// admin operations; actorID is the admin making the change
+	ListUsers(afterID, limit int) ([]*users.User, error)
+	ChangeRole(actorID, userID int, role users.Role) (*users.User, error)
+	SetUserDisabled(actorID, userID int, disabled bool) (*users.User, error)
 }
 
 // persistence operations for users.
@@ -14,4 +34,84 @@ type UserRepository interface {
 	CreateUser(user *users.User) error
 	GetUserByEmail(email string) (*users.User, error)
 	GetUserByID(id int) (*users.User, error)
+	GetUsersByIDs(ids []int) ([]*users.User, error)
+	GetActiveUsersByUsernames(usernames []string) ([]*users.User, error)
+	ListUsers(afterID, limit int) ([]*users.User, error)
+	// both revoke the user's sessions in the same transaction (SetUserDisabled only when disabling)
+	UpdateUserRole(id int, role users.Role) (*users.User, error)
+	SetUserDisabled(id int, disabled bool) (*users.User, error)
+	MarkEmailVerified(id int, email string) error
+	// false if the user was sent a verification email less than cooldown ago
+	ClaimVerificationEmail(id int, cooldown time.Duration) (bool, error)
+}
+
+// forgotten password recovery.
+type PasswordResetUsecase interface {
+	// emails a reset link if the address belongs to an active account. says nothing either way.
+	ForgotPassword(email string) error
+	// sets a new password using an emailed token and logs the account out everywhere
+	ResetPassword(token, newPassword string) error
+}
+
+// email address confirmation.
+type EmailVerificationUsecase interface {
+	// emails the user a signed link that confirms their address, unless they
+	// got one within the cooldown
+	SendVerificationEmail(user *users.User) error
+	VerifyEmail(token string) error
+	// sends a new link if the address belongs to an unverified account. says nothing either way.
+	ResendVerificationEmail(email string) error
+}
+
+// teams (organizations) and their membership. actorID is the user making the call.
+type TeamUsecase interface {
+	CreateTeam(actorID int, name string) (*teams.Team, error)
+	ListTeams(actorID int) ([]*teams.Membership, error)
+	GetTeam(actorID, teamID int) (*teams.Team, []*teams.Member, error)
+	InviteMember(actorID, teamID int, email string, role teams.MemberRole) (*teams.Invite, error)
+	AcceptInvite(actorID int, token string) (*teams.Membership, error)
+	ChangeMemberRole(actorID, teamID, userID int, role teams.MemberRole) error
+	// members may always remove themselves, i.e. leave
+	RemoveMember(actorID, teamID, userID int) error
+	// for other services; no permission checks
+	GetMemberRole(teamID, userID int) (teams.MemberRole, error)
+	ListMemberships(userID int) ([]*teams.Membership, error)
+}
+
+type TeamRepository interface {
+	CreateTeam(team *teams.Team) error
+	GetTeam(id int) (*teams.Team, error)
+	ListTeamsForUser(userID int) ([]*teams.Membership, error)
+	GetMemberRole(teamID, userID int) (teams.MemberRole, error)
+	ListMembers(teamID int) ([]*teams.Member, error)
+	// both fail with ErrLastOwner rather than leave a team without an owner
+	UpdateMemberRole(teamID, userID int, role teams.MemberRole) error
+	RemoveMember(teamID, userID int) error
+	CreateInvite(invite *teams.Invite) error
+	GetOpenInvite(tokenHash string) (*teams.Invite, error)
+	AcceptInvite(invite *teams.Invite, userID int) error
+}
+
+type PasswordResetRepository interface {
+	// fails with passwordreset.ErrTooSoon if the user got a token less than cooldown ago
+	CreatePasswordResetToken(userID int, tokenHash string, expiresAt time.Time, cooldown time.Duration) error
+	// uses up the token, sets its user's password and revokes their sessions, or does none of it
+	ResetPasswordWithToken(tokenHash, passwordHash string) (*passwordreset.Token, error)
+}
+
+// hands emails to the notification service
+type EmailPublisher interface {
+	PublishEmail(email *events.Email) error
+}
+
+// persistence operations for login sessions and their refresh tokens.
+type SessionRepository interface {
+	CreateSession(userID int) (*sessions.Session, error)
+	GetSession(id string) (*sessions.Session, error)
+	RevokeSession(id string, reason string) error
+	RevokeUserSessions(userID int, reason string) error
+	CreateRefreshToken(sessionID string, tokenHash string, expiresAt time.Time) error
+	// uses up an unused refresh token and stores the next one of its session, or does neither
+	RotateRefreshToken(tokenHash, nextHash string, nextExpiresAt time.Time) (*sessions.RefreshToken, error)
+	GetRefreshToken(tokenHash string) (*sessions.RefreshToken, error)
 }
@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"shared/events"
+	"time"
+	"user_service/internal/core/passwordreset"
+	"user_service/internal/core/users"
+	"user_service/pkg/hashpassword"
+	"user_service/pkg/securetoken"
+)
+
+type passwordResetUsecase struct {
+	userRepo  UserRepository
+	resetRepo PasswordResetRepository
+	emails    EmailPublisher
+	resetURL  string
+	tokenTTL  time.Duration
+	cooldown  time.Duration // least time between two reset emails to the same user
+}
+
+func NewPasswordResetUsecase(userRepo UserRepository, resetRepo PasswordResetRepository, emails EmailPublisher, resetURL string, tokenTTL, cooldown time.Duration) PasswordResetUsecase {
+	return &passwordResetUsecase{
+		userRepo:  userRepo,
+		resetRepo: resetRepo,
+		emails:    emails,
+		resetURL:  resetURL,
+		tokenTTL:  tokenTTL,
+		cooldown:  cooldown,
+	}
+}
+
+// unknown and disabled addresses get the same silent success as real ones,
+// so the endpoint can't be used to find out who has an account
+func (uc *passwordResetUsecase) ForgotPassword(email string) error {
+	user, err := uc.userRepo.GetUserByEmail(email)
+	if errors.Is(err, users.ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not look up user: %w", err)
+	}
+	if user.IsDisabled() {
+		return nil
+	}
+
+	token, tokenHash, err := securetoken.Generate()
+	if err != nil {
+		return err
+	}
+	expiresAt := time.Now().Add(uc.tokenTTL)
+	err = uc.resetRepo.CreatePasswordResetToken(user.ID, tokenHash, expiresAt, uc.cooldown)
+	if errors.Is(err, passwordreset.ErrTooSoon) {
+		// the last email is still on its way; asking again mustn't flood the inbox
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not start password reset: %w", err)
+	}
+
+	msg := events.NewEmail(events.EmailPasswordReset, user.ID, user.Email, user.Username)
+	msg.Link = uc.resetURL + "?token=" + url.QueryEscape(token)
+	msg.ExpiresAt = expiresAt.UTC()
+	if err := uc.emails.PublishEmail(msg); err != nil {
+		// not reported to the caller, that would tell them the account exists.
+		// the user can simply ask again.
+		log.Printf("Could not send password reset email for user %d: %v", user.ID, err)
+	}
+	return nil
+}
+
+func (uc *passwordResetUsecase) ResetPassword(token, newPassword string) error {
+	if len(newPassword) < users.MinPasswordLength {
+		return users.ErrPasswordTooShort
+	}
+	passwordHash, err := hashpassword.HashPassword(newPassword)
+	if err != nil {
+		return fmt.Errorf("could not hash password: %w", err)
+	}
+	// the token is used up together with the password change, so it can't be
+	// used twice, and isn't lost if the change fails. whoever knew the old
+	// password is logged out in the same step.
+	reset, err := uc.resetRepo.ResetPasswordWithToken(securetoken.Hash(token), passwordHash)
+	if err != nil {
+		return err
+	}
+	log.Printf("Password reset for user %d", reset.UserID)
+	return nil
+}
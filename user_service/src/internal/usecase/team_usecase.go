@@ -0,0 +1,198 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"shared/events"
+	"strings"
+	"time"
+	"user_service/internal/core/teams"
+	"user_service/pkg/securetoken"
+)
+
+type teamUsecase struct {
+	teamRepo  TeamRepository
+	userRepo  UserRepository
+	emails    EmailPublisher
+	inviteURL string
+	inviteTTL time.Duration
+}
+
+func NewTeamUsecase(teamRepo TeamRepository, userRepo UserRepository, emails EmailPublisher, inviteURL string, inviteTTL time.Duration) TeamUsecase {
+	return &teamUsecase{
+		teamRepo:  teamRepo,
+		userRepo:  userRepo,
+		emails:    emails,
+		inviteURL: inviteURL,
+		inviteTTL: inviteTTL,
+	}
+}
+
+func (uc *teamUsecase) CreateTeam(actorID int, name string) (*teams.Team, error) {
+	name, err := teams.ValidateName(name)
+	if err != nil {
+		return nil, err
+	}
+	team := &teams.Team{Name: name, CreatedBy: actorID}
+	if err := uc.teamRepo.CreateTeam(team); err != nil {
+		return nil, fmt.Errorf("could not create team: %w", err)
+	}
+	return team, nil
+}
+
+func (uc *teamUsecase) ListTeams(actorID int) ([]*teams.Membership, error) {
+	return uc.ListMemberships(actorID)
+}
+
+// only members see a team; to anyone else it doesn't exist
+func (uc *teamUsecase) GetTeam(actorID, teamID int) (*teams.Team, []*teams.Member, error) {
+	if _, err := uc.memberRole(teamID, actorID); err != nil {
+		return nil, nil, err
+	}
+	team, err := uc.teamRepo.GetTeam(teamID)
+	if err != nil {
+		return nil, nil, err
+	}
+	members, err := uc.teamRepo.ListMembers(teamID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return team, members, nil
+}
+
+func (uc *teamUsecase) InviteMember(actorID, teamID int, email string, role teams.MemberRole) (*teams.Invite, error) {
+	actorRole, err := uc.memberRole(teamID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if !actorRole.CanManageMembers() {
+		return nil, teams.ErrForbidden
+	}
+	// otherwise an admin could invite a second account of theirs as owner
+	if role == teams.MemberRoleOwner && !actorRole.CanChangeRoles() {
+		return nil, teams.ErrForbidden
+	}
+	team, err := uc.teamRepo.GetTeam(teamID)
+	if err != nil {
+		return nil, err
+	}
+	inviter, err := uc.userRepo.GetUserByID(actorID)
+	if err != nil {
+		return nil, fmt.Errorf("could not load inviting user: %w", err)
+	}
+
+	token, tokenHash, err := securetoken.Generate()
+	if err != nil {
+		return nil, err
+	}
+	invite := &teams.Invite{
+		TeamID:    teamID,
+		Email:     strings.TrimSpace(email),
+		Role:      role,
+		InvitedBy: actorID,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(uc.inviteTTL),
+	}
+	if err := uc.teamRepo.CreateInvite(invite); err != nil {
+		return nil, fmt.Errorf("could not invite member: %w", err)
+	}
+
+	msg := events.NewEmail(events.EmailTeamInvite, 0, invite.Email, "")
+	if invitee, err := uc.userRepo.GetUserByEmail(invite.Email); err == nil {
+		msg.UserID, msg.Username = invitee.ID, invitee.Username
+	}
+	msg.Link = uc.inviteURL + "?token=" + url.QueryEscape(token)
+	msg.ExpiresAt = invite.ExpiresAt.UTC()
+	msg.TeamName = team.Name
+	msg.InvitedBy = inviter.Username
+	if err := uc.emails.PublishEmail(msg); err != nil {
+		return nil, fmt.Errorf("could not send invite: %w", err)
+	}
+	log.Printf("User %d invited %s to team %d as %s", actorID, invite.Email, teamID, role)
+	return invite, nil
+}
+
+// the invite is bound to an email address, so it can only be accepted by the
+// account with that address, and only once the account has proven it owns it
+func (uc *teamUsecase) AcceptInvite(actorID int, token string) (*teams.Membership, error) {
+	invite, err := uc.teamRepo.GetOpenInvite(securetoken.Hash(token))
+	if err != nil {
+		return nil, err
+	}
+	user, err := uc.userRepo.GetUserByID(actorID)
+	if err != nil {
+		return nil, fmt.Errorf("could not load user: %w", err)
+	}
+	if !strings.EqualFold(user.Email, invite.Email) {
+		return nil, teams.ErrInviteEmail
+	}
+	// whatever REQUIRE_VERIFIED_EMAIL says: anyone can register with the invited address
+	if !user.EmailVerified {
+		return nil, teams.ErrInviteUnverified
+	}
+	if err := uc.teamRepo.AcceptInvite(invite, actorID); err != nil {
+		return nil, err
+	}
+	team, err := uc.teamRepo.GetTeam(invite.TeamID)
+	if err != nil {
+		return nil, err
+	}
+	return &teams.Membership{Team: *team, Role: invite.Role, JoinedAt: time.Now()}, nil
+}
+
+func (uc *teamUsecase) ChangeMemberRole(actorID, teamID, userID int, role teams.MemberRole) error {
+	actorRole, err := uc.memberRole(teamID, actorID)
+	if err != nil {
+		return err
+	}
+	if !actorRole.CanChangeRoles() {
+		return teams.ErrForbidden
+	}
+	// the repository refuses to demote the last owner
+	return uc.teamRepo.UpdateMemberRole(teamID, userID, role)
+}
+
+func (uc *teamUsecase) RemoveMember(actorID, teamID, userID int) error {
+	actorRole, err := uc.memberRole(teamID, actorID)
+	if err != nil {
+		return err
+	}
+	target, err := uc.teamRepo.GetMemberRole(teamID, userID)
+	if err != nil {
+		return err
+	}
+	if actorID != userID {
+		if !actorRole.CanManageMembers() {
+			return teams.ErrForbidden
+		}
+		// admins can't remove owners
+		if target == teams.MemberRoleOwner && !actorRole.CanChangeRoles() {
+			return teams.ErrForbidden
+		}
+	}
+	// the repository refuses to remove the last owner
+	return uc.teamRepo.RemoveMember(teamID, userID)
+}
+
+func (uc *teamUsecase) GetMemberRole(teamID, userID int) (teams.MemberRole, error) {
+	return uc.teamRepo.GetMemberRole(teamID, userID)
+}
+
+func (uc *teamUsecase) ListMemberships(userID int) ([]*teams.Membership, error) {
+	memberships, err := uc.teamRepo.ListTeamsForUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("could not list teams: %w", err)
+	}
+	return memberships, nil
+}
+
+// the actor's role in the team. non-members get ErrTeamNotFound, so team ids can't be probed.
+func (uc *teamUsecase) memberRole(teamID, actorID int) (teams.MemberRole, error) {
+	role, err := uc.teamRepo.GetMemberRole(teamID, actorID)
+	if errors.Is(err, teams.ErrNotMember) {
+		return "", teams.ErrTeamNotFound
+	}
+	return role, err
+}
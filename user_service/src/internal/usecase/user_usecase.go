@@ -1,22 +1,41 @@
 package usecase
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"strings"
+	"time"
+	"user_service/internal/core/sessions"
 	"user_service/internal/core/users"
 	"user_service/pkg/generatejwt"
 	"user_service/pkg/hashpassword"
+	"user_service/pkg/securetoken"
 )
 
+var ErrBatchTooLarge = errors.New("batch too large")
+
 type userUsecase struct {
 	userRepo     UserRepository
-	jwtSecretKey string
+	sessionRepo  SessionRepository
+	verification EmailVerificationUsecase
+	cfg          UserUsecaseConfig
+}
+
+type UserUsecaseConfig struct {
+	JWTSecretKey    string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	// refuse to log in accounts whose email address hasn't been confirmed
+	RequireVerifiedEmail bool
 }
 
-func NewUserUsecase(repo UserRepository, jwtSecret string) UserUsecase {
+func NewUserUsecase(repo UserRepository, sessionRepo SessionRepository, verification EmailVerificationUsecase, cfg UserUsecaseConfig) UserUsecase {
 	return &userUsecase{
 		userRepo:     repo,
-		jwtSecretKey: jwtSecret,
+		sessionRepo:  sessionRepo,
+		verification: verification,
+		cfg:          cfg,
 	}
 }
 
@@ -30,25 +49,140 @@ func (uc *userUsecase) RegisterUser(user *users.User) error {
 	if err != nil {
 		return fmt.Errorf("could not register user: %w", err)
 	}
+	// the account exists either way; a lost email can be sent again from the resend endpoint
+	if err := uc.verification.SendVerificationEmail(user); err != nil {
+		log.Printf("Could not send verification email for user %d: %v", user.ID, err)
+	}
 	return nil
 }
 
-func (uc *userUsecase) LoginUser(email, password string) (string, error) {
+// starts a new session and returns its first token pair
+func (uc *userUsecase) LoginUser(email, password string) (*sessions.TokenPair, error) {
 	user, err := uc.userRepo.GetUserByEmail(email)
 	if err != nil {
-		return "", fmt.Errorf("invalid email or password")
+		return nil, fmt.Errorf("invalid email or password")
 	}
 	log.Printf("Password hash from DB: %s", user.PasswordHash)
 	comparisonResult := hashpassword.CheckPasswordHash(password, user.PasswordHash)
 	log.Printf("Password comparison result: %v", comparisonResult)
 	if !comparisonResult {
-		return "", fmt.Errorf("invalid email or password")
+		return nil, fmt.Errorf("invalid email or password")
+	}
+	if user.IsDisabled() {
+		return nil, users.ErrUserDisabled
+	}
+	if uc.cfg.RequireVerifiedEmail && !user.EmailVerified {
+		return nil, users.ErrEmailNotVerified
+	}
+	session, err := uc.sessionRepo.CreateSession(user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("could not start session: %w", err)
+	}
+	return uc.issueTokens(user, session.ID)
+}
+
+func (uc *userUsecase) RefreshTokens(refreshToken string) (*sessions.TokenPair, error) {
+	tokenHash := securetoken.Hash(refreshToken)
+	token, err := uc.sessionRepo.GetRefreshToken(tokenHash)
+	if err != nil {
+		if errors.Is(err, sessions.ErrInvalidRefreshToken) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("could not refresh tokens: %w", err)
+	}
+	if token.UsedAt != nil {
+		return nil, uc.revokeReusedToken(token)
+	}
+	if time.Now().After(token.ExpiresAt) {
+		return nil, sessions.ErrInvalidRefreshToken
+	}
+
+	session, err := uc.sessionRepo.GetSession(token.SessionID)
+	if err != nil {
+		return nil, fmt.Errorf("could not refresh tokens: %w", err)
+	}
+	if session.IsRevoked() {
+		return nil, sessions.ErrSessionRevoked
+	}
+	user, err := uc.userRepo.GetUserByID(session.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("could not refresh tokens: %w", err)
+	}
+	if user.IsDisabled() {
+		return nil, users.ErrUserDisabled
+	}
+
+	// the new access token carries the user's current role
+	pair, nextHash, err := uc.newTokenPair(user, session.ID)
+	if err != nil {
+		return nil, err
+	}
+	_, err = uc.sessionRepo.RotateRefreshToken(tokenHash, nextHash, time.Now().Add(uc.cfg.RefreshTokenTTL))
+	if errors.Is(err, sessions.ErrInvalidRefreshToken) {
+		// used by a concurrent request since we looked it up
+		return nil, uc.revokeReusedToken(token)
 	}
-	token, err := generatejwt.GenerateToken(user, uc.jwtSecretKey)
 	if err != nil {
-		return "", fmt.Errorf("could not generate token: %w", err)
+		return nil, fmt.Errorf("could not refresh tokens: %w", err)
+	}
+	return pair, nil
+}
+
+// a token that exists but was already used has most likely been stolen:
+// whoever presents it next, the whole family goes
+func (uc *userUsecase) revokeReusedToken(token *sessions.RefreshToken) error {
+	if err := uc.sessionRepo.RevokeSession(token.SessionID, "refresh token reuse"); err != nil {
+		return fmt.Errorf("could not revoke session: %w", err)
+	}
+	log.Printf("Refresh token reuse detected, revoked session %s", token.SessionID)
+	return sessions.ErrRefreshTokenReused
+}
+
+func (uc *userUsecase) Logout(sessionID string) error {
+	if err := uc.sessionRepo.RevokeSession(sessionID, "logout"); err != nil {
+		return fmt.Errorf("could not log out: %w", err)
 	}
-	return token, nil
+	return nil
+}
+
+func (uc *userUsecase) IsSessionActive(sessionID string) (bool, error) {
+	session, err := uc.sessionRepo.GetSession(sessionID)
+	if errors.Is(err, sessions.ErrSessionNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("could not check session: %w", err)
+	}
+	return !session.IsRevoked(), nil
+}
+
+// signs an access token and stores a fresh refresh token for the session
+func (uc *userUsecase) issueTokens(user *users.User, sessionID string) (*sessions.TokenPair, error) {
+	pair, refreshHash, err := uc.newTokenPair(user, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.sessionRepo.CreateRefreshToken(sessionID, refreshHash, time.Now().Add(uc.cfg.RefreshTokenTTL)); err != nil {
+		return nil, fmt.Errorf("could not store refresh token: %w", err)
+	}
+	return pair, nil
+}
+
+// a fresh access and refresh token; the hash of the refresh token is what gets stored
+func (uc *userUsecase) newTokenPair(user *users.User, sessionID string) (*sessions.TokenPair, string, error) {
+	accessToken, err := generatejwt.GenerateToken(user, sessionID, uc.cfg.JWTSecretKey, uc.cfg.AccessTokenTTL)
+	if err != nil {
+		return nil, "", fmt.Errorf("could not generate token: %w", err)
+	}
+	refreshToken, refreshHash, err := securetoken.Generate()
+	if err != nil {
+		return nil, "", err
+	}
+	return &sessions.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    uc.cfg.AccessTokenTTL,
+	}, refreshHash, nil
 }
 
 func (uc *userUsecase) GetProfile(userID int) (*users.User, error) {
@@ -58,3 +192,130 @@ func (uc *userUsecase) GetProfile(userID int) (*users.User, error) {
 	}
 	return user, nil
 }
+
+// the largest batch accepted by BatchGetUsers
+const MaxBatchSize = 500
+
+func (uc *userUsecase) BatchGetUsers(userIDs []int) ([]*users.User, []int, error) {
+	// dedupe while keeping the caller's order for the missing list
+	seen := make(map[int]bool, len(userIDs))
+	unique := make([]int, 0, len(userIDs))
+	for _, id := range userIDs {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	if len(unique) > MaxBatchSize {
+		return nil, nil, fmt.Errorf("%w: at most %d ids per batch, got %d", ErrBatchTooLarge, MaxBatchSize, len(unique))
+	}
+	if len(unique) == 0 {
+		return nil, nil, nil
+	}
+
+	found, err := uc.userRepo.GetUsersByIDs(unique)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not get users: %w", err)
+	}
+
+	foundIDs := make(map[int]bool, len(found))
+	for _, user := range found {
+		foundIDs[user.ID] = true
+	}
+	var missing []int
+	for _, id := range unique {
+		if !foundIDs[id] {
+			missing = append(missing, id)
+		}
+	}
+	return found, missing, nil
+}
+
+// also limited to MaxBatchSize names
+func (uc *userUsecase) GetUsersByUsernames(usernames []string) ([]*users.User, []string, error) {
+	seen := make(map[string]bool, len(usernames))
+	unique := make([]string, 0, len(usernames))
+	for _, name := range usernames {
+		name = strings.ToLower(name)
+		if name != "" && !seen[name] {
+			seen[name] = true
+			unique = append(unique, name)
+		}
+	}
+	if len(unique) > MaxBatchSize {
+		return nil, nil, fmt.Errorf("%w: at most %d usernames per batch, got %d", ErrBatchTooLarge, MaxBatchSize, len(unique))
+	}
+	if len(unique) == 0 {
+		return nil, nil, nil
+	}
+
+	found, err := uc.userRepo.GetActiveUsersByUsernames(unique)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not get users: %w", err)
+	}
+
+	// a name shared by several accounts doesn't tell which one is meant, so it
+	// resolves to none of them. the unique index rules that out, but the
+	// lookup mustn't pick a user at random should it ever happen.
+	matches := make(map[string]int, len(found))
+	for _, user := range found {
+		matches[strings.ToLower(user.Username)]++
+	}
+	var resolved []*users.User
+	for _, user := range found {
+		if matches[strings.ToLower(user.Username)] == 1 {
+			resolved = append(resolved, user)
+		}
+	}
+	var missing []string
+	for _, name := range unique {
+		if matches[name] != 1 {
+			missing = append(missing, name)
+		}
+	}
+	return resolved, missing, nil
+}
+
+// the largest page ListUsers returns
+const MaxListUsersLimit = 100
+
+func (uc *userUsecase) ListUsers(afterID, limit int) ([]*users.User, error) {
+	if limit < 1 || limit > MaxListUsersLimit {
+		limit = MaxListUsersLimit
+	}
+	userList, err := uc.userRepo.ListUsers(afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("could not list users: %w", err)
+	}
+	return userList, nil
+}
+
+// the user's sessions are revoked, so the new role is in effect on their next login
+// instead of whenever their current access token expires
+func (uc *userUsecase) ChangeRole(actorID, userID int, role users.Role) (*users.User, error) {
+	if !role.IsValid() {
+		return nil, fmt.Errorf("%w %q", users.ErrInvalidRole, role)
+	}
+	if actorID == userID {
+		return nil, users.ErrCannotModifySelf
+	}
+	user, err := uc.userRepo.UpdateUserRole(userID, role)
+	if err != nil {
+		return nil, fmt.Errorf("could not change role: %w", err)
+	}
+	log.Printf("User %d changed role of user %d to %s", actorID, userID, role)
+	return user, nil
+}
+
+// disabling an account also logs it out everywhere
+func (uc *userUsecase) SetUserDisabled(actorID, userID int, disabled bool) (*users.User, error) {
+	if actorID == userID {
+		return nil, users.ErrCannotModifySelf
+	}
+	user, err := uc.userRepo.SetUserDisabled(userID, disabled)
+	if err != nil {
+		return nil, fmt.Errorf("could not update account: %w", err)
+	}
+	log.Printf("User %d set disabled=%v on user %d", actorID, disabled, userID)
+	return user, nil
+}
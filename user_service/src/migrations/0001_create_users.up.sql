@@ -1,3 +1,4 @@
+-- IF NOT EXISTS because databases created before versioned migrations already have the table
 CREATE TABLE IF NOT EXISTS users (
     id SERIAL PRIMARY KEY,
     username VARCHAR(255) NOT NULL,
@@ -0,0 +1,7 @@
+-- accounts that exist before verification was introduced count as verified;
+-- only new registrations start out unverified
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified BOOLEAN NOT NULL DEFAULT true;
+ALTER TABLE users ALTER COLUMN email_verified SET DEFAULT false;
+
+-- when the last verification link went out, to rate-limit resends
+ALTER TABLE users ADD COLUMN IF NOT EXISTS verification_sent_at TIMESTAMPTZ;
@@ -0,0 +1,2 @@
+-- renamed accounts keep their new names
+DROP INDEX IF EXISTS idx_users_username_lower;
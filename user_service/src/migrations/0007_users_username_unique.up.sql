@@ -0,0 +1,11 @@
+-- usernames used to be unique only by convention. accounts that share a name
+-- with an older one, ignoring case, get their id appended to it.
+UPDATE users SET username = left(username, 255 - length('_' || id)) || '_' || id
+WHERE id IN (
+    SELECT id FROM (
+        SELECT id, row_number() OVER (PARTITION BY lower(username) ORDER BY id) AS n FROM users
+    ) ranked
+    WHERE n > 1
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username_lower ON users (lower(username));
@@ -0,0 +1,9 @@
+package migrations
+
+import "embed"
+
+// the versioned *.up.sql / *.down.sql files, compiled into the binary so
+// migrations don't depend on the working directory.
+//
+//go:embed *.sql
+var FS embed.FS
@@ -9,14 +9,19 @@ import (
 )
 
 type Claims struct {
-	UserID int `json:"user_id"`
+	UserID    int    `json:"user_id"`
+	SessionID string `json:"sid"` // session the token was issued for, checked for revocation
+	Role      string `json:"role"`
 	jwt.StandardClaims
 }
 
-func GenerateToken(user *users.User, secretKey string) (string, error) {
-	expirationTime := time.Now().Add(24 * time.Hour)
+// issues a short-lived access token bound to a session
+func GenerateToken(user *users.User, sessionID string, secretKey string, ttl time.Duration) (string, error) {
+	expirationTime := time.Now().Add(ttl)
 	claims := &Claims{
-		UserID: user.ID,
+		UserID:    user.ID,
+		SessionID: sessionID,
+		Role:      string(user.Role),
 		StandardClaims: jwt.StandardClaims{
 			ExpiresAt: expirationTime.Unix(),
 		},